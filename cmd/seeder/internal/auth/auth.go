@@ -1,22 +1,42 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// TokenProvider fetches access tokens from Logto using client_credentials flow.
+// refreshBefore is how long before expiry a cached token is replaced. Tokens
+// with a shorter lifetime are refreshed halfway through it instead.
+const refreshBefore = time.Minute
+
+// defaultTokenLifetime is assumed when the token response has no positive
+// expires_in; it matches Logto's default access token TTL.
+const defaultTokenLifetime = time.Hour
+
+// TokenProvider fetches access tokens from Logto using client_credentials flow
+// and caches them until shortly before they expire. It implements
+// credentials.PerRPCCredentials, so every gRPC call carries a fresh token.
 type TokenProvider struct {
 	logtoURL     string
 	clientID     string
 	clientSecret string
 	resource     string
 	httpClient   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
 }
 
 // NewTokenProvider creates a TokenProvider for the given Logto instance.
@@ -30,8 +50,35 @@ func NewTokenProvider(logtoURL, clientID, clientSecret, resource string) *TokenP
 	}
 }
 
-// FetchToken obtains an access token using the client_credentials grant.
-func (p *TokenProvider) FetchToken() (string, error) {
+// Token returns the cached access token, fetching a new one when none is
+// cached or the cached one is about to expire.
+func (p *TokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.refreshAt) {
+		return p.token, nil
+	}
+
+	token, expiresIn, err := p.FetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	p.token = token
+	p.refreshAt = time.Now().Add(expiresIn - min(refreshBefore, expiresIn/2))
+	return token, nil
+}
+
+// Invalidate drops the cached token so the next call fetches a new one.
+func (p *TokenProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = ""
+}
+
+// FetchToken obtains an access token and its lifetime using the client_credentials grant.
+func (p *TokenProvider) FetchToken(ctx context.Context) (string, time.Duration, error) {
 	data := url.Values{
 		"grant_type":    {"client_credentials"},
 		"client_id":     {p.clientID},
@@ -40,27 +87,67 @@ func (p *TokenProvider) FetchToken() (string, error) {
 		"scope":         {"products:read products:write categories:read categories:write attributes:read attributes:write images:write"},
 	}
 
-	resp, err := p.httpClient.PostForm(p.logtoURL+"/oidc/token", data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.logtoURL+"/oidc/token", strings.NewReader(data.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("token request failed: %w", err)
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("token request returned %d: %s", resp.StatusCode, string(body))
+		return "", 0, fmt.Errorf("token request returned %d: %s", resp.StatusCode, string(body))
 	}
 
 	var tokenResp struct {
 		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("failed to decode token response: %w", err)
+		return "", 0, fmt.Errorf("failed to decode token response: %w", err)
 	}
 
 	if tokenResp.AccessToken == "" {
-		return "", fmt.Errorf("received empty access token")
+		return "", 0, fmt.Errorf("received empty access token")
 	}
 
-	return tokenResp.AccessToken, nil
+	expiresIn := time.Duration(tokenResp.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = defaultTokenLifetime
+	}
+	return tokenResp.AccessToken, expiresIn, nil
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials.
+func (p *TokenProvider) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := p.Token(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to obtain access token: %v", err)
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials. Local
+// clusters are reached over plaintext, so the token is sent either way.
+func (p *TokenProvider) RequireTransportSecurity() bool {
+	return false
+}
+
+// RetryUnauthenticated returns an interceptor that drops the cached token and
+// retries a call once when the server rejects it as Unauthenticated, e.g.
+// after the token was revoked or expired earlier than advertised.
+func (p *TokenProvider) RetryUnauthenticated() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) != codes.Unauthenticated {
+			return err
+		}
+		p.Invalidate()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
 }
@@ -3,46 +3,171 @@ package config
 import (
 	"flag"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config represents the seeder runtime configuration.
 type Config struct {
-	CatalogGRPCAddr     string
-	ImageGRPCAddr       string
-	LogtoURL            string
-	ClientID            string
-	ClientSecret        string
-	APIResource         string
-	TenantSlug          string
-	StorageHostOverride string
+	CatalogGRPCAddr     string `yaml:"catalog-grpc-addr"`
+	ImageGRPCAddr       string `yaml:"image-grpc-addr"`
+	LogtoURL            string `yaml:"logto-url"`
+	ClientID            string `yaml:"client-id"`
+	ClientSecret        string `yaml:"client-secret"`
+	APIResource         string `yaml:"api-resource"`
+	TenantSlug          string `yaml:"tenant-slug"`
+	StorageHostOverride string `yaml:"storage-host-override"`
+	ImageCacheFile      string `yaml:"image-cache"`
+
+	// gRPC transport security. TLS is the default; GRPCPlaintext opts out of it.
+	GRPCPlaintext   bool   `yaml:"grpc-plaintext"`
+	GRPCSystemRoots bool   `yaml:"grpc-system-roots"`
+	GRPCCAFile      string `yaml:"grpc-ca-file"`
+	GRPCCertFile    string `yaml:"grpc-cert-file"`
+	GRPCKeyFile     string `yaml:"grpc-key-file"`
+	GRPCServerName  string `yaml:"grpc-server-name"`
+
+	// Image preprocessing: uploads larger than MaxImageSize bytes are rejected,
+	// images are downscaled to MaxImageDimension pixels on the longer side, and
+	// ImageFormat (jpeg, png, webp, avif) transcodes them. Zero/empty disables each step.
+	MaxImageSize      int    `yaml:"max-image-size"`
+	MaxImageDimension int    `yaml:"max-image-dimension"`
+	ImageFormat       string `yaml:"image-format"`
+
+	// PlaceholderImages renders a generated main image for products without assets
+	// instead of forcing them disabled.
+	PlaceholderImages bool `yaml:"placeholder-images"`
+
+	// Concurrency is the number of entities upserted in parallel per phase;
+	// QPS caps catalog/image RPCs per second (0 disables the limit).
+	Concurrency int     `yaml:"concurrency"`
+	QPS         float64 `yaml:"qps"`
+
+	// MaxAttempts bounds retries of transient RPC and storage failures;
+	// CallTimeout is the deadline of each attempt (0 = none).
+	MaxAttempts int           `yaml:"max-attempts"`
+	CallTimeout time.Duration `yaml:"call-timeout"`
+
+	// ConflictPolicy decides what happens when an update loses a version race:
+	// fail, refetch-and-retry (overwrite) or skip (keep the concurrent edit).
+	ConflictPolicy string `yaml:"on-conflict"`
+
+	// KeepGoing records entity failures and continues instead of aborting,
+	// skipping entities that depend on a failed one.
+	KeepGoing bool `yaml:"keep-going"`
+
+	// CheckpointFile records completed entities and uploaded images as the run
+	// goes, or names a directory holding one checkpoint per tenant; Resume
+	// continues from it when the tenant and dataset are unchanged.
+	CheckpointFile string `yaml:"checkpoint"`
+	Resume         bool   `yaml:"resume"`
+
+	// Prune removes catalog entities whose IDs are missing from the seed data.
+	Prune      bool   `yaml:"prune"`
+	PruneMode  string `yaml:"prune-mode"`
+	PruneLimit int    `yaml:"prune-limit"`
+	Force      bool   `yaml:"force"`
+
+	// WaitTimeout bounds how long to wait for Logto and the gRPC services to
+	// become ready before starting (0 skips the check).
+	WaitTimeout time.Duration `yaml:"wait-timeout"`
+
+	// GCMinAge is how old a seed draft image must be before gc-images deletes it.
+	GCMinAge time.Duration `yaml:"gc-min-age"`
 }
 
+// Commands accepted as the first positional argument.
+const (
+	CommandSeed     = "seed"
+	CommandValidate = "validate"
+	CommandSchema   = "schema"
+	CommandGCImages = "gc-images"
+)
+
 // Args holds all CLI arguments.
 type Args struct {
-	Config    *Config
-	DataDir   string
-	AssetsDir string
+	Config    *Config `yaml:",inline"`
+	Command   string  `yaml:"-"`
+	DataDir   string  `yaml:"data-dir"`
+	AssetsDir string  `yaml:"assets-dir"`
+	SchemaDir string  `yaml:"schema-dir"`
+	Plan      bool    `yaml:"plan"`
+
+	// ReportFile and JUnitFile receive the run report as JSON and JUnit XML
+	// ("-" for stdout); empty skips the report.
+	ReportFile string `yaml:"report"`
+	JUnitFile  string `yaml:"report-junit"`
+
+	// LogFormat (text or json) and LogLevel (debug, info, warn, error) configure
+	// the structured logger.
+	LogFormat string `yaml:"log-format"`
+	LogLevel  string `yaml:"log-level"`
 }
 
-// Parse returns configuration from CLI flags with env variable defaults.
-func Parse() *Args {
-	args := &Args{
-		Config: &Config{},
+// Parse returns configuration from CLI flags with env variable defaults, which
+// in turn default to the --config YAML file and the --profile it selects.
+// An optional positional command (seed, validate, schema, gc-images) selects what the binary does.
+func Parse() (*Args, error) {
+	configFile := preScan(os.Args[1:], "config", "SEEDER_CONFIG")
+	profile := preScan(os.Args[1:], "profile", "SEEDER_PROFILE")
+	args, err := loadBase(configFile, profile)
+	if err != nil {
+		return nil, err
 	}
 
-	flag.StringVar(&args.Config.CatalogGRPCAddr, "catalog-grpc-addr", envOr("CATALOG_GRPC_ADDR", "ecommerce-catalog-service.127.0.0.1.nip.io:8080"), "Catalog service gRPC address (host:port)")
-	flag.StringVar(&args.Config.ImageGRPCAddr, "image-grpc-addr", envOr("IMAGE_GRPC_ADDR", "ecommerce-image-service.127.0.0.1.nip.io:8080"), "Image service gRPC address (host:port)")
-	flag.StringVar(&args.Config.LogtoURL, "logto-url", envOr("LOGTO_URL", "http://localhost:3001"), "Logto OIDC issuer URL")
-	flag.StringVar(&args.Config.ClientID, "client-id", envOr("LOGTO_CLIENT_ID", ""), "Logto M2M application client ID")
-	flag.StringVar(&args.Config.ClientSecret, "client-secret", envOr("LOGTO_CLIENT_SECRET", ""), "Logto M2M application client secret")
-	flag.StringVar(&args.Config.APIResource, "api-resource", envOr("API_RESOURCE_INDICATOR", "https://api.sokolshop.com"), "Logto API resource indicator")
-	flag.StringVar(&args.Config.TenantSlug, "tenant-slug", envOr("TENANT_SLUG", ""), "Tenant slug to seed data for (sets X-Tenant-Slug header)")
-	flag.StringVar(&args.Config.StorageHostOverride, "storage-host-override", envOr("STORAGE_HOST_OVERRIDE", ""), "Override presigned URL host (e.g. minio:9000 for in-cluster access)")
-	flag.StringVar(&args.DataDir, "data-dir", envOr("DATA_DIR", "data"), "Path to seed data directory")
-	flag.StringVar(&args.AssetsDir, "assets-dir", envOr("ASSETS_DIR", "assets"), "Path to assets directory")
+	flag.StringVar(&configFile, "config", configFile, "Path to a YAML file setting any of these flags by name")
+	flag.StringVar(&profile, "profile", profile, "Named connection profile: local, production or one defined in --config")
+	flag.StringVar(&args.Config.CatalogGRPCAddr, "catalog-grpc-addr", envOr("CATALOG_GRPC_ADDR", args.Config.CatalogGRPCAddr), "Catalog service gRPC address (host:port)")
+	flag.StringVar(&args.Config.ImageGRPCAddr, "image-grpc-addr", envOr("IMAGE_GRPC_ADDR", args.Config.ImageGRPCAddr), "Image service gRPC address (host:port)")
+	flag.StringVar(&args.Config.LogtoURL, "logto-url", envOr("LOGTO_URL", args.Config.LogtoURL), "Logto OIDC issuer URL")
+	flag.StringVar(&args.Config.ClientID, "client-id", envOr("LOGTO_CLIENT_ID", args.Config.ClientID), "Logto M2M application client ID")
+	flag.StringVar(&args.Config.ClientSecret, "client-secret", envOr("LOGTO_CLIENT_SECRET", args.Config.ClientSecret), "Logto M2M application client secret")
+	flag.StringVar(&args.Config.APIResource, "api-resource", envOr("API_RESOURCE_INDICATOR", args.Config.APIResource), "Logto API resource indicator")
+	flag.StringVar(&args.Config.TenantSlug, "tenant-slug", envOr("TENANT_SLUG", args.Config.TenantSlug), "Tenant slug to seed data for (sets X-Tenant-Slug header)")
+	flag.BoolVar(&args.Config.GRPCPlaintext, "grpc-plaintext", envBoolOr("GRPC_PLAINTEXT", args.Config.GRPCPlaintext), "Connect to gRPC services without TLS")
+	flag.BoolVar(&args.Config.GRPCSystemRoots, "grpc-system-roots", envBoolOr("GRPC_SYSTEM_ROOTS", args.Config.GRPCSystemRoots), "Trust the system root CAs for gRPC TLS")
+	flag.StringVar(&args.Config.GRPCCAFile, "grpc-ca-file", envOr("GRPC_CA_FILE", args.Config.GRPCCAFile), "PEM CA bundle to trust for gRPC TLS")
+	flag.StringVar(&args.Config.GRPCCertFile, "grpc-cert-file", envOr("GRPC_CERT_FILE", args.Config.GRPCCertFile), "Client certificate for gRPC mTLS")
+	flag.StringVar(&args.Config.GRPCKeyFile, "grpc-key-file", envOr("GRPC_KEY_FILE", args.Config.GRPCKeyFile), "Client private key for gRPC mTLS")
+	flag.StringVar(&args.Config.GRPCServerName, "grpc-server-name", envOr("GRPC_SERVER_NAME", args.Config.GRPCServerName), "Override the server name verified in gRPC TLS certificates")
+	flag.StringVar(&args.Config.StorageHostOverride, "storage-host-override", envOr("STORAGE_HOST_OVERRIDE", args.Config.StorageHostOverride), "Override presigned URL host (e.g. minio:9000 for in-cluster access)")
+	flag.StringVar(&args.Config.ImageCacheFile, "image-cache", envOr("IMAGE_CACHE", args.Config.ImageCacheFile), "Path to a file persisting uploaded image IDs by content hash across runs, or a directory holding one per tenant")
+	flag.IntVar(&args.Config.MaxImageSize, "max-image-size", envIntOr("MAX_IMAGE_SIZE", args.Config.MaxImageSize), "Maximum image file size in bytes (0 = unlimited)")
+	flag.IntVar(&args.Config.MaxImageDimension, "max-image-dimension", envIntOr("MAX_IMAGE_DIMENSION", args.Config.MaxImageDimension), "Downscale images so the longer side is at most this many pixels (0 = keep size)")
+	flag.StringVar(&args.Config.ImageFormat, "image-format", envOr("IMAGE_FORMAT", args.Config.ImageFormat), "Transcode images before upload: jpeg, png, webp or avif (webp/avif need cwebp/avifenc on PATH; the seeder image has neither)")
+	flag.BoolVar(&args.Config.PlaceholderImages, "placeholder-images", envBoolOr("PLACEHOLDER_IMAGES", args.Config.PlaceholderImages), "Generate placeholder images for products without assets")
+	flag.IntVar(&args.Config.Concurrency, "concurrency", envIntOr("CONCURRENCY", args.Config.Concurrency), "Number of entities upserted in parallel")
+	flag.Float64Var(&args.Config.QPS, "qps", envFloatOr("QPS", args.Config.QPS), "Maximum gRPC requests per second (0 = unlimited)")
+	flag.IntVar(&args.Config.MaxAttempts, "max-attempts", envIntOr("MAX_ATTEMPTS", args.Config.MaxAttempts), "Maximum attempts per RPC or storage upload on transient failures")
+	flag.DurationVar(&args.Config.CallTimeout, "call-timeout", envDurationOr("CALL_TIMEOUT", args.Config.CallTimeout), "Deadline for each RPC or upload attempt (0 = none)")
+	flag.StringVar(&args.Config.ConflictPolicy, "on-conflict", envOr("ON_CONFLICT", args.Config.ConflictPolicy), "Version conflict policy: fail, refetch-and-retry or skip")
+	flag.BoolVar(&args.Config.KeepGoing, "keep-going", envBoolOr("KEEP_GOING", args.Config.KeepGoing), "Continue after entity failures and report them all at the end")
+	flag.StringVar(&args.Config.CheckpointFile, "checkpoint", envOr("CHECKPOINT", args.Config.CheckpointFile), "Path to a checkpoint file recording the progress of the run, or a directory holding one per tenant")
+	flag.BoolVar(&args.Config.Resume, "resume", envBoolOr("RESUME", args.Config.Resume), "Skip entities completed by the run recorded in --checkpoint")
+	flag.BoolVar(&args.Config.Prune, "prune", envBoolOr("PRUNE", args.Config.Prune), "Remove catalog entities that are not in the seed data")
+	flag.StringVar(&args.Config.PruneMode, "prune-mode", envOr("PRUNE_MODE", args.Config.PruneMode), "How to prune entities: delete or disable")
+	flag.IntVar(&args.Config.PruneLimit, "prune-limit", envIntOr("PRUNE_LIMIT", args.Config.PruneLimit), "Maximum number of entities pruned without --force")
+	flag.BoolVar(&args.Config.Force, "force", envBoolOr("FORCE", args.Config.Force), "Allow pruning more entities than --prune-limit")
+	flag.DurationVar(&args.Config.WaitTimeout, "wait-timeout", envDurationOr("WAIT_TIMEOUT", args.Config.WaitTimeout), "How long to wait for Logto and gRPC services to become ready (0 = don't wait)")
+	flag.DurationVar(&args.Config.GCMinAge, "gc-min-age", envDurationOr("GC_MIN_AGE", args.Config.GCMinAge), "Minimum age of draft images deleted by gc-images")
+	flag.StringVar(&args.DataDir, "data-dir", envOr("DATA_DIR", args.DataDir), "Path to seed data directory")
+	flag.StringVar(&args.AssetsDir, "assets-dir", envOr("ASSETS_DIR", args.AssetsDir), "Path to assets directory")
+	flag.BoolVar(&args.Plan, "plan", envBoolOr("PLAN", args.Plan), "Print what seeding would create/update without writing anything")
+	flag.StringVar(&args.ReportFile, "report", envOr("REPORT", args.ReportFile), "Write a JSON run report to this path (- for stdout)")
+	flag.StringVar(&args.JUnitFile, "report-junit", envOr("REPORT_JUNIT", args.JUnitFile), "Write a JUnit XML run report to this path (- for stdout)")
+	flag.StringVar(&args.LogFormat, "log-format", envOr("LOG_FORMAT", args.LogFormat), "Log output format: text or json")
+	flag.StringVar(&args.LogLevel, "log-level", envOr("LOG_LEVEL", args.LogLevel), "Minimum log level: debug, info, warn or error")
+	flag.StringVar(&args.SchemaDir, "schema-dir", args.SchemaDir, "Output directory for the schema command")
 	flag.Parse()
 
-	return args
+	args.Command = CommandSeed
+	if flag.NArg() > 0 {
+		args.Command = flag.Arg(0)
+		// Allow flags after the command, e.g. `seeder validate --data-dir=data`.
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+	}
+
+	return args, nil
 }
 
 func envOr(key, defaultVal string) string {
@@ -51,3 +176,31 @@ func envOr(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+func envBoolOr(key string, defaultVal bool) bool {
+	if val, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return val
+	}
+	return defaultVal
+}
+
+func envIntOr(key string, defaultVal int) int {
+	if val, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return val
+	}
+	return defaultVal
+}
+
+func envFloatOr(key string, defaultVal float64) float64 {
+	if val, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return val
+	}
+	return defaultVal
+}
+
+func envDurationOr(key string, defaultVal time.Duration) time.Duration {
+	if val, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return val
+	}
+	return defaultVal
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// profiles bundle the connection settings of the environments the seeder is
+// usually run against. A --config file may extend them or add its own.
+var profiles = map[string]func(*Args){
+	// local runs from the host against the local cluster through its nip.io
+	// ingresses; MinIO is published on localhost:9000.
+	"local": func(args *Args) {
+		args.Config.CatalogGRPCAddr = "ecommerce-catalog-service.127.0.0.1.nip.io:8080"
+		args.Config.ImageGRPCAddr = "ecommerce-image-service.127.0.0.1.nip.io:8080"
+		args.Config.LogtoURL = "http://localhost:3001"
+		args.Config.StorageHostOverride = "localhost:9000"
+		args.Config.GRPCPlaintext = true
+	},
+	// production runs in-cluster next to the services, like the seeder CronJob.
+	"production": func(args *Args) {
+		args.Config.CatalogGRPCAddr = "ecommerce-catalog-service:8080"
+		args.Config.ImageGRPCAddr = "ecommerce-image-service:8080"
+		args.Config.LogtoURL = "http://logto:3001"
+		args.Config.StorageHostOverride = ""
+		args.Config.GRPCPlaintext = true
+	},
+}
+
+// fileConfig is the layout of a --config file: any flag by name, the profile
+// to apply when --profile is not given, and custom or extended profiles.
+type fileConfig struct {
+	Args     `yaml:",inline"`
+	Profile  string               `yaml:"profile"`
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+// defaultArgs returns the values used when neither a flag, an env variable
+// nor the config file sets an option.
+func defaultArgs() *Args {
+	return &Args{
+		Config: &Config{
+			CatalogGRPCAddr: "ecommerce-catalog-service.127.0.0.1.nip.io:8080",
+			ImageGRPCAddr:   "ecommerce-image-service.127.0.0.1.nip.io:8080",
+			LogtoURL:        "http://localhost:3001",
+			APIResource:     "https://api.sokolshop.com",
+			GRPCSystemRoots: true,
+			MaxImageSize:    10 << 20,
+			Concurrency:     4,
+			MaxAttempts:     4,
+			CallTimeout:     30 * time.Second,
+			ConflictPolicy:  "fail",
+			PruneMode:       "disable",
+			PruneLimit:      10,
+			WaitTimeout:     2 * time.Minute,
+			GCMinAge:        24 * time.Hour,
+		},
+		DataDir:   "data",
+		AssetsDir: "assets",
+		SchemaDir: "schema",
+		LogFormat: "text",
+		LogLevel:  "info",
+	}
+}
+
+// loadBase resolves the values flags and env variables fall back to:
+// defaults, then the top level of the config file, then the built-in profile,
+// then the file's section for the profile. A selected profile thus wins over
+// the file's top-level keys.
+func loadBase(path, profile string) (*Args, error) {
+	var file fileConfig
+	file.Args = *defaultArgs()
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := decodeStrict(raw, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	args := &file.Args
+
+	if profile == "" {
+		profile = file.Profile
+	}
+	section, inFile := file.Profiles[profile]
+	if profile != "" {
+		apply, builtin := profiles[profile]
+		if !builtin && !inFile {
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(profileNames(file.Profiles), ", "))
+		}
+		if builtin {
+			apply(args)
+		}
+	}
+
+	if inFile {
+		out, err := yaml.Marshal(&section)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read profile %s: %w", profile, err)
+		}
+		if err := decodeStrict(out, args); err != nil {
+			return nil, fmt.Errorf("failed to parse profile %s in config file %s: %w", profile, path, err)
+		}
+	}
+
+	return args, nil
+}
+
+// decodeStrict decodes YAML onto out, keeping the values of absent keys and
+// rejecting keys that match no option.
+func decodeStrict(raw []byte, out any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func profileNames(custom map[string]yaml.Node) []string {
+	names := make([]string, 0, len(profiles)+len(custom))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	for name := range custom {
+		if _, ok := profiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// preScan finds the value of a flag before flag.Parse runs, so the config file
+// and profile can provide the defaults of the other flags.
+func preScan(arguments []string, name, envKey string) string {
+	value := os.Getenv(envKey)
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		if arg == "--" {
+			break
+		}
+		key, val, hasVal := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || key != name {
+			continue
+		}
+		if hasVal {
+			value = val
+		} else if i+1 < len(arguments) {
+			value = arguments[i+1]
+			i++
+		}
+	}
+	return value
+}
@@ -1,8 +1,12 @@
 package data
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 )
@@ -15,45 +19,63 @@ type SeedData struct {
 }
 
 type Category struct {
-	ID         string              `json:"id"`
+	ID         string              `json:"id,omitempty"`
 	Name       string              `json:"name"`
 	Enabled    bool                `json:"enabled"`
 	Attributes []CategoryAttribute `json:"attributes,omitempty"`
 }
 
+// CategoryAttribute references its attribute either by ID or by slug (Attribute);
+// the slug is resolved to AttributeID at load time.
 type CategoryAttribute struct {
-	AttributeID string `json:"attributeId"`
-	Role        string `json:"role"`
+	AttributeID string `json:"attributeId,omitempty" oneof:"attribute"`
+	Attribute   string `json:"attribute,omitempty" oneof:"attribute"`
+	Role        string `json:"role" enum:"variant,specification"`
 	SortOrder   int    `json:"sortOrder,omitempty"`
 	Filterable  bool   `json:"filterable"`
 	Searchable  bool   `json:"searchable"`
 }
 
 type Product struct {
-	ID          string             `json:"id"`
+	ID          string             `json:"id,omitempty"`
 	Name        string             `json:"name"`
 	Description string             `json:"description"`
 	Price       float64            `json:"price"`
 	Quantity    int                `json:"quantity"`
-	CategoryID  string             `json:"categoryId"`
+	CategoryID  string             `json:"categoryId,omitempty" oneof:"category,optional"`
+	Category    string             `json:"category,omitempty" oneof:"category,optional"`
 	Enabled     bool               `json:"enabled"`
 	Attributes  []ProductAttribute `json:"attributes,omitempty"`
+	Images      []ProductImage     `json:"images,omitempty"`
 }
 
+// ProductImage is an asset file attached to a product. Without an explicit
+// role the first image in sort order becomes the main one, the rest gallery.
+type ProductImage struct {
+	File      string `json:"file"`
+	Role      string `json:"role,omitempty" enum:"main,gallery,other"`
+	Alt       string `json:"alt,omitempty"`
+	SortOrder int    `json:"sortOrder,omitempty"`
+}
+
+// ProductAttribute carries exactly one value field; fields tagged oneof:"value"
+// form a union that strict decoding and the JSON Schema both enforce. Like
+// CategoryAttribute, it references its attribute by ID or by slug.
 type ProductAttribute struct {
-	AttributeID      string   `json:"attributeId"`
-	OptionSlugValue  string   `json:"optionSlugValue,omitempty"`
-	OptionSlugValues []string `json:"optionSlugValues,omitempty"`
-	NumericValue     *float64 `json:"numericValue,omitempty"`
-	TextValue        string   `json:"textValue,omitempty"`
-	BooleanValue     *bool    `json:"booleanValue,omitempty"`
+	AttributeID      string   `json:"attributeId,omitempty" oneof:"attribute"`
+	Attribute        string   `json:"attribute,omitempty" oneof:"attribute"`
+	OptionSlugValue  string   `json:"optionSlugValue,omitempty" oneof:"value"`
+	OptionSlugValues []string `json:"optionSlugValues,omitempty" oneof:"value"`
+	NumericValue     *float64 `json:"numericValue,omitempty" oneof:"value"`
+	TextValue        string   `json:"textValue,omitempty" oneof:"value"`
+	BooleanValue     *bool    `json:"booleanValue,omitempty" oneof:"value"`
 }
 
 type Attribute struct {
-	ID      string            `json:"id"`
+	ID      string            `json:"id,omitempty"`
 	Name    string            `json:"name"`
 	Slug    string            `json:"slug"`
-	Type    string            `json:"type"`
+	Type    string            `json:"type" enum:"single,multiple,range,boolean,text"`
 	Unit    string            `json:"unit,omitempty"`
 	Enabled bool              `json:"enabled"`
 	Options []AttributeOption `json:"options,omitempty"`
@@ -66,38 +88,78 @@ type AttributeOption struct {
 	SortOrder int    `json:"sortOrder,omitempty"`
 }
 
-// LoadFromDir loads seed data from a directory containing categories.json and products.json.
-func LoadFromDir(dir string) (*SeedData, error) {
-	categories, err := loadFile[Category](filepath.Join(dir, "categories.json"))
+// Seed file names inside the data directory.
+const (
+	categoriesFile = "categories.json"
+	productsFile   = "products.json"
+	attributesFile = "attributes.json"
+)
+
+// LoadFromDir loads seed data from a directory containing categories.json, products.json
+// and attributes.json, assigns IDs derived for tenantSlug to entities without one,
+// and resolves symbolic attribute/category references to IDs.
+func LoadFromDir(dir, tenantSlug string) (*SeedData, error) {
+	categories, err := loadFile[Category](filepath.Join(dir, categoriesFile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load categories: %w", err)
 	}
 
-	products, err := loadFile[Product](filepath.Join(dir, "products.json"))
+	products, err := loadFile[Product](filepath.Join(dir, productsFile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load products: %w", err)
 	}
 
-	attributes, err := loadFile[Attribute](filepath.Join(dir, "attributes.json"))
+	attributes, err := loadFile[Attribute](filepath.Join(dir, attributesFile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load attributes: %w", err)
 	}
 
-	return &SeedData{
+	sd := &SeedData{
 		Categories: categories,
 		Products:   products,
 		Attributes: attributes,
-	}, nil
+	}
+	assignIDs(sd, tenantSlug)
+
+	if err := resolveReferences(sd); err != nil {
+		return nil, err
+	}
+
+	return sd, nil
+}
+
+// Hash returns a SHA-256 fingerprint of the loaded seed data, including the
+// derived IDs and resolved references. Asset file contents are not covered.
+func (sd *SeedData) Hash() (string, error) {
+	raw, err := json.Marshal(sd)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal seed data: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
 }
 
+// loadFile strictly decodes a JSON array of T: unknown or miscased fields, trailing data,
+// unknown enum strings and ambiguous value unions are rejected.
 func loadFile[T any](path string) ([]T, error) {
-	data, err := os.ReadFile(path)
+	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	file := filepath.Base(path)
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
 
 	var items []T
-	if err := json.Unmarshal(data, &items); err != nil {
+	if err := dec.Decode(&items); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%s: unexpected data after top-level array", file)
+	}
+
+	if err := checkStrict(file, raw, items); err != nil {
 		return nil, err
 	}
 
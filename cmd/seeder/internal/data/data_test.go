@@ -0,0 +1,115 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFileStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			json: `[{"name": "Color", "slug": "color", "type": "single", "enabled": true,
+				"options": [{"name": "Red", "slug": "red", "colorCode": "#f00"}]}]`,
+		},
+		{
+			name:    "unknown field",
+			json:    `[{"name": "Color", "slug": "color", "type": "single", "enabled": true, "colour": "red"}]`,
+			wantErr: `unknown field "colour"`,
+		},
+		{
+			name:    "unknown nested field",
+			json:    `[{"name": "Color", "slug": "color", "type": "single", "enabled": true, "options": [{"name": "Red", "slug": "red", "hex": "#f00"}]}]`,
+			wantErr: `unknown field "hex"`,
+		},
+		{
+			name:    "key differing in case",
+			json:    `[{"Name": "Color", "slug": "color", "type": "single", "enabled": true}]`,
+			wantErr: `attributes.json[0]: unknown field "Name"`,
+		},
+		{
+			name:    "unknown enum value",
+			json:    `[{"name": "Color", "slug": "color", "type": "colour", "enabled": true}]`,
+			wantErr: `attributes.json[0].type: unknown value "colour"`,
+		},
+		{
+			name:    "trailing data",
+			json:    `[] []`,
+			wantErr: "unexpected data after top-level array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), attributesFile)
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := loadFile[Attribute](path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("loadFile() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFileStrictOneof(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{
+			name: "one value",
+			json: `[{"attribute": "color", "optionSlugValue": "red"}]`,
+		},
+		{
+			name:    "no value",
+			json:    `[{"attribute": "color"}]`,
+			wantErr: "exactly one value field must be set, got []",
+		},
+		{
+			name:    "two values",
+			json:    `[{"attribute": "color", "optionSlugValue": "red", "textValue": "red"}]`,
+			wantErr: "exactly one value field must be set, got [optionSlugValue, textValue]",
+		},
+		{
+			name:    "attribute by ID and slug",
+			json:    `[{"attributeId": "a1", "attribute": "color", "textValue": "red"}]`,
+			wantErr: "exactly one attribute field must be set, got [attributeId, attribute]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "values.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := loadFile[ProductAttribute](path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("loadFile() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
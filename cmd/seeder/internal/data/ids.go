@@ -0,0 +1,33 @@
+package data
+
+import (
+	"github.com/google/uuid"
+)
+
+// idNamespace is the UUIDv5 namespace for IDs derived from seed natural keys.
+var idNamespace = uuid.MustParse("6f1c3a52-8d0e-4b7a-9c2f-5e4d3b2a1f00")
+
+// assignIDs fills in empty IDs with a stable UUIDv5 derived from the tenant and
+// the entity's natural key (attribute slug, category name, product name), so
+// reruns update the same entities instead of creating duplicates.
+func assignIDs(sd *SeedData, tenantSlug string) {
+	for i := range sd.Attributes {
+		if sd.Attributes[i].ID == "" {
+			sd.Attributes[i].ID = deriveID(tenantSlug, "attribute", sd.Attributes[i].Slug)
+		}
+	}
+	for i := range sd.Categories {
+		if sd.Categories[i].ID == "" {
+			sd.Categories[i].ID = deriveID(tenantSlug, "category", sd.Categories[i].Name)
+		}
+	}
+	for i := range sd.Products {
+		if sd.Products[i].ID == "" {
+			sd.Products[i].ID = deriveID(tenantSlug, "product", sd.Products[i].Name)
+		}
+	}
+}
+
+func deriveID(tenantSlug, kind, key string) string {
+	return uuid.NewSHA1(idNamespace, []byte(tenantSlug+"/"+kind+"/"+key)).String()
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDeriveID(t *testing.T) {
+	base := deriveID("acme", "product", "Laptop")
+
+	tests := []struct {
+		name              string
+		tenant, kind, key string
+		wantSame          bool
+	}{
+		{name: "same inputs", tenant: "acme", kind: "product", key: "Laptop", wantSame: true},
+		{name: "other tenant", tenant: "globex", kind: "product", key: "Laptop"},
+		{name: "other kind", tenant: "acme", kind: "category", key: "Laptop"},
+		{name: "other key", tenant: "acme", kind: "product", key: "laptop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := deriveID(tt.tenant, tt.kind, tt.key)
+			if _, err := uuid.Parse(id); err != nil {
+				t.Fatalf("deriveID() = %q, not a UUID: %v", id, err)
+			}
+			if (id == base) != tt.wantSame {
+				t.Fatalf("deriveID() = %q, base %q, want same = %v", id, base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestAssignIDsKeepsExplicitIDs(t *testing.T) {
+	sd := &SeedData{
+		Attributes: []Attribute{{ID: "explicit", Slug: "color"}, {Slug: "size"}},
+	}
+	assignIDs(sd, "acme")
+
+	if got := sd.Attributes[0].ID; got != "explicit" {
+		t.Errorf("explicit ID = %q, want it kept", got)
+	}
+	if got, want := sd.Attributes[1].ID, deriveID("acme", "attribute", "size"); got != want {
+		t.Errorf("derived ID = %q, want %q", got, want)
+	}
+}
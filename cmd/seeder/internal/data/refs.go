@@ -0,0 +1,68 @@
+package data
+
+import (
+	"strings"
+)
+
+// resolveReferences replaces symbolic references with IDs: CategoryAttribute.Attribute
+// and ProductAttribute.Attribute by attribute slug, Product.Category by category
+// name (matched case-insensitively, spaces as dashes: "home-appliances").
+// Unknown and ambiguous references are reported together as a *ValidationError.
+func resolveReferences(sd *SeedData) error {
+	attributes := make(map[string][]string)
+	for _, a := range sd.Attributes {
+		attributes[a.Slug] = append(attributes[a.Slug], a.ID)
+	}
+	categories := make(map[string][]string)
+	for _, c := range sd.Categories {
+		key := categoryKey(c.Name)
+		categories[key] = append(categories[key], c.ID)
+	}
+
+	v := &validator{}
+
+	for i := range sd.Categories {
+		cat := &sd.Categories[i]
+		for j := range cat.Attributes {
+			ca := &cat.Attributes[j]
+			if ca.Attribute != "" {
+				ca.AttributeID = v.resolveRef(categoriesFile, i, "attribute", ca.Attribute, attributes[ca.Attribute])
+			}
+		}
+	}
+
+	for i := range sd.Products {
+		prod := &sd.Products[i]
+		if prod.Category != "" {
+			prod.CategoryID = v.resolveRef(productsFile, i, "category", prod.Category, categories[categoryKey(prod.Category)])
+		}
+		for j := range prod.Attributes {
+			pa := &prod.Attributes[j]
+			if pa.Attribute != "" {
+				pa.AttributeID = v.resolveRef(productsFile, i, "attribute", pa.Attribute, attributes[pa.Attribute])
+			}
+		}
+	}
+
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+func (v *validator) resolveRef(file string, index int, kind, ref string, ids []string) string {
+	switch len(ids) {
+	case 1:
+		return ids[0]
+	case 0:
+		v.addf(file, index, "unknown %s reference %q", kind, ref)
+	default:
+		v.addf(file, index, "ambiguous %s reference %q matches %s", kind, ref, strings.Join(ids, ", "))
+	}
+	return ""
+}
+
+// categoryKey normalizes a category name or reference for matching.
+func categoryKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
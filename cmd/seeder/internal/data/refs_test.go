@@ -0,0 +1,73 @@
+package data
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestResolveReferences(t *testing.T) {
+	sd := &SeedData{
+		Attributes: []Attribute{{ID: "a-color", Slug: "color"}, {ID: "a-size", Slug: "size"}},
+		Categories: []Category{
+			{ID: "c-home", Name: "Home Appliances", Attributes: []CategoryAttribute{{Attribute: "color"}, {AttributeID: "a-size"}}},
+		},
+		Products: []Product{
+			{Name: "Kettle", Category: " home-APPLIANCES", Attributes: []ProductAttribute{{Attribute: "size"}}},
+			{Name: "Toaster", CategoryID: "c-home"},
+		},
+	}
+
+	if err := resolveReferences(sd); err != nil {
+		t.Fatalf("resolveReferences() error = %v", err)
+	}
+
+	cat := sd.Categories[0]
+	if got := []string{cat.Attributes[0].AttributeID, cat.Attributes[1].AttributeID}; !slices.Equal(got, []string{"a-color", "a-size"}) {
+		t.Errorf("category attribute IDs = %v, want [a-color a-size]", got)
+	}
+	if got := sd.Products[0].CategoryID; got != "c-home" {
+		t.Errorf("product category ID = %q, want c-home", got)
+	}
+	if got := sd.Products[0].Attributes[0].AttributeID; got != "a-size" {
+		t.Errorf("product attribute ID = %q, want a-size", got)
+	}
+	if got := sd.Products[1].CategoryID; got != "c-home" {
+		t.Errorf("explicit category ID = %q, want it kept", got)
+	}
+}
+
+func TestResolveReferencesReportsAllViolations(t *testing.T) {
+	sd := &SeedData{
+		Attributes: []Attribute{{ID: "a1", Slug: "color"}, {ID: "a2", Slug: "color"}},
+		Categories: []Category{
+			{ID: "c1", Name: "Phones", Attributes: []CategoryAttribute{{Attribute: "weight"}}},
+			{ID: "c2", Name: "phones"},
+		},
+		Products: []Product{
+			{Name: "Phone", Category: "Phones", Attributes: []ProductAttribute{{Attribute: "color"}}},
+		},
+	}
+
+	err := resolveReferences(sd)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("resolveReferences() error = %v, want *ValidationError", err)
+	}
+
+	var got []string
+	for _, v := range verr.Violations {
+		got = append(got, v.String())
+	}
+	want := []string{
+		categoriesFile + `[0]: unknown attribute reference "weight"`,
+		productsFile + `[0]: ambiguous category reference "Phones" matches c1, c2`,
+		productsFile + `[0]: ambiguous attribute reference "color" matches a1, a2`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("violations =\n%q\nwant\n%q", got, want)
+	}
+	if sd.Products[0].CategoryID != "" {
+		t.Errorf("unresolved category ID = %q, want empty", sd.Products[0].CategoryID)
+	}
+}
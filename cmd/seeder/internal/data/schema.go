@@ -0,0 +1,137 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// WriteSchemas generates a JSON Schema for every seed file into dir
+// (e.g. attributes.schema.json), derived from the types in this package.
+func WriteSchemas(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create schema dir: %w", err)
+	}
+
+	files := []struct {
+		name string
+		item reflect.Type
+	}{
+		{attributesFile, reflect.TypeFor[Attribute]()},
+		{categoriesFile, reflect.TypeFor[Category]()},
+		{productsFile, reflect.TypeFor[Product]()},
+	}
+
+	for _, f := range files {
+		schemaFile := strings.TrimSuffix(f.name, ".json") + ".schema.json"
+		schema := map[string]any{
+			"$schema": schemaDialect,
+			"$id":     schemaFile,
+			"title":   f.name,
+			"type":    "array",
+			"items":   typeSchema(f.item),
+		}
+
+		b, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", schemaFile, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, schemaFile), append(b, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", schemaFile, err)
+		}
+	}
+	return nil
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		panic(fmt.Sprintf("schema: unsupported kind %s", t.Kind()))
+	}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+	groups := make(map[string][]string)
+	optional := make(map[string]bool)
+	var groupOrder []string
+
+	for _, f := range reflect.VisibleFields(t) {
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+
+		prop := typeSchema(f.Type)
+		if enum, ok := f.Tag.Lookup("enum"); ok {
+			prop["enum"] = strings.Split(enum, ",")
+		}
+		properties[name] = prop
+
+		if group, opt, ok := oneofTag(f); ok {
+			if _, seen := groups[group]; !seen {
+				groupOrder = append(groupOrder, group)
+			}
+			groups[group] = append(groups[group], name)
+			optional[group] = optional[group] || opt
+			continue
+		}
+		if !strings.Contains(f.Tag.Get("json"), ",omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+
+	// A single union maps to oneOf; several need allOf of oneOfs.
+	var allOf []any
+	for _, group := range groupOrder {
+		allOf = append(allOf, map[string]any{"oneOf": unionSchema(groups[group], optional[group])})
+	}
+	switch len(allOf) {
+	case 0:
+	case 1:
+		schema["oneOf"] = allOf[0].(map[string]any)["oneOf"]
+	default:
+		schema["allOf"] = allOf
+	}
+	return schema
+}
+
+// unionSchema requires exactly one of fields; an optional union also allows none.
+func unionSchema(fields []string, optional bool) []any {
+	branches := make([]any, 0, len(fields)+1)
+	for _, name := range fields {
+		branches = append(branches, map[string]any{"required": []string{name}})
+	}
+	if optional {
+		branches = append(branches, map[string]any{"not": map[string]any{"anyOf": slices.Clone(branches)}})
+	}
+	return branches
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// checkStrict enforces the constraints that encoding/json cannot express:
+// object keys must match the JSON field names exactly (the decoder matches
+// them case-insensitively), string fields tagged `enum:"a,b"` must hold one
+// of the listed values unless omitted, and each `oneof:"group"` must have
+// exactly one non-zero field (at most one for `oneof:"group,optional"`).
+func checkStrict[T any](file string, raw []byte, items []T) error {
+	var generic []any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	var errs []error
+	itemType := reflect.TypeFor[T]()
+	for i := range items {
+		path := fmt.Sprintf("%s[%d]", file, i)
+		checkKeys(itemType, generic[i], path, &errs)
+		walkStrict(reflect.ValueOf(items[i]), path, &errs)
+	}
+	return errors.Join(errs...)
+}
+
+func checkKeys(t reflect.Type, raw any, path string, errs *[]error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		list, _ := raw.([]any)
+		for i, elem := range list {
+			checkKeys(t.Elem(), elem, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Struct:
+		obj, _ := raw.(map[string]any)
+		fields := make(map[string]reflect.Type)
+		for _, f := range reflect.VisibleFields(t) {
+			if name := jsonName(f); name != "" {
+				fields[name] = f.Type
+			}
+		}
+
+		keys := slices.Sorted(maps.Keys(obj))
+		for _, key := range keys {
+			ft, ok := fields[key]
+			if !ok {
+				*errs = append(*errs, fmt.Errorf("%s: unknown field %q", path, key))
+				continue
+			}
+			checkKeys(ft, obj[key], path+"."+key, errs)
+		}
+	}
+}
+
+func walkStrict(v reflect.Value, path string, errs *[]error) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			walkStrict(v.Elem(), path, errs)
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			walkStrict(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Struct:
+		groups := make(map[string][]string)
+		optional := make(map[string]bool)
+		var groupOrder []string
+
+		for _, f := range reflect.VisibleFields(v.Type()) {
+			name := jsonName(f)
+			if name == "" {
+				continue
+			}
+			fv := v.FieldByIndex(f.Index)
+			fieldPath := path + "." + name
+
+			omitted := fv.IsZero() && strings.Contains(f.Tag.Get("json"), ",omitempty")
+			if enum, ok := f.Tag.Lookup("enum"); ok && fv.Kind() == reflect.String && !omitted {
+				allowed := strings.Split(enum, ",")
+				if !slices.Contains(allowed, fv.String()) {
+					*errs = append(*errs, fmt.Errorf("%s: unknown value %q (expected one of %s)",
+						fieldPath, fv.String(), strings.Join(allowed, ", ")))
+				}
+			}
+
+			if group, opt, ok := oneofTag(f); ok {
+				if _, seen := groups[group]; !seen {
+					groupOrder = append(groupOrder, group)
+					groups[group] = []string{}
+				}
+				optional[group] = optional[group] || opt
+				if !fv.IsZero() {
+					groups[group] = append(groups[group], name)
+				}
+			}
+
+			walkStrict(fv, fieldPath, errs)
+		}
+
+		for _, group := range groupOrder {
+			set := groups[group]
+			switch {
+			case optional[group] && len(set) > 1:
+				*errs = append(*errs, fmt.Errorf("%s: at most one %s field may be set, got [%s]",
+					path, group, strings.Join(set, ", ")))
+			case !optional[group] && len(set) != 1:
+				*errs = append(*errs, fmt.Errorf("%s: exactly one %s field must be set, got [%s]",
+					path, group, strings.Join(set, ", ")))
+			}
+		}
+	}
+}
+
+// jsonName returns the JSON property name of a struct field, or "" if the field is not serialized.
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// oneofTag parses a `oneof:"group[,optional]"` struct tag.
+func oneofTag(f reflect.StructField) (group string, optional, ok bool) {
+	tag, ok := f.Tag.Lookup("oneof")
+	if !ok {
+		return "", false, false
+	}
+	group, opts, _ := strings.Cut(tag, ",")
+	return group, opts == "optional", true
+}
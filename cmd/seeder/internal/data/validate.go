@@ -0,0 +1,221 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation describes a single referential-integrity problem in the seed files.
+type Violation struct {
+	File    string
+	Index   int
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s[%d]: %s", v.File, v.Index, v.Message)
+}
+
+// ValidationError aggregates every violation found by Validate.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "seed data has %d violation(s):", len(e.Violations))
+	for _, v := range e.Violations {
+		b.WriteString("\n  ")
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+// Validate checks cross-file references in the seed data without touching the network:
+// category and product references to attributes and categories, option slugs,
+// value kinds matching the attribute type, product attributes bound to the category
+// and at most one main image per product.
+// It returns a *ValidationError listing all violations, or nil.
+func Validate(sd *SeedData) error {
+	v := &validator{
+		attributes: make(map[string]*Attribute, len(sd.Attributes)),
+		categories: make(map[string]*Category, len(sd.Categories)),
+	}
+
+	for i := range sd.Attributes {
+		attr := &sd.Attributes[i]
+		if attr.ID == "" {
+			continue
+		}
+		if _, dup := v.attributes[attr.ID]; dup {
+			v.addf(attributesFile, i, "duplicate attribute id %q", attr.ID)
+			continue
+		}
+		v.attributes[attr.ID] = attr
+	}
+
+	for i := range sd.Categories {
+		cat := &sd.Categories[i]
+		if cat.ID != "" {
+			if _, dup := v.categories[cat.ID]; dup {
+				v.addf(categoriesFile, i, "duplicate category id %q", cat.ID)
+			} else {
+				v.categories[cat.ID] = cat
+			}
+		}
+		v.validateCategory(i, cat)
+	}
+
+	productIDs := make(map[string]bool, len(sd.Products))
+	for i := range sd.Products {
+		prod := &sd.Products[i]
+		if prod.ID != "" {
+			if productIDs[prod.ID] {
+				v.addf(productsFile, i, "duplicate product id %q", prod.ID)
+			}
+			productIDs[prod.ID] = true
+		}
+		v.validateProduct(i, prod)
+	}
+
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+type validator struct {
+	attributes map[string]*Attribute
+	categories map[string]*Category
+	violations []Violation
+}
+
+func (v *validator) addf(file string, index int, format string, args ...any) {
+	v.violations = append(v.violations, Violation{
+		File:    file,
+		Index:   index,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateCategory(index int, cat *Category) {
+	for _, ca := range cat.Attributes {
+		if _, ok := v.attributes[ca.AttributeID]; !ok {
+			v.addf(categoriesFile, index, "category %q references unknown attribute %q", cat.Name, ca.AttributeID)
+		}
+	}
+}
+
+func (v *validator) validateProduct(index int, prod *Product) {
+	var bound map[string]bool
+	if prod.CategoryID != "" {
+		cat, ok := v.categories[prod.CategoryID]
+		if !ok {
+			v.addf(productsFile, index, "product %q references unknown category %q", prod.Name, prod.CategoryID)
+		} else {
+			bound = make(map[string]bool, len(cat.Attributes))
+			for _, ca := range cat.Attributes {
+				bound[ca.AttributeID] = true
+			}
+		}
+	}
+
+	mainImages := 0
+	for _, img := range prod.Images {
+		if img.Role == "main" {
+			mainImages++
+		}
+	}
+	if mainImages > 1 {
+		v.addf(productsFile, index, "product %q has %d main images, expected at most one", prod.Name, mainImages)
+	}
+
+	for _, pa := range prod.Attributes {
+		attr, ok := v.attributes[pa.AttributeID]
+		if !ok {
+			v.addf(productsFile, index, "product %q references unknown attribute %q", prod.Name, pa.AttributeID)
+			continue
+		}
+
+		switch {
+		case prod.CategoryID == "":
+			v.addf(productsFile, index, "product %q sets attribute %q but has no category", prod.Name, attr.Slug)
+		case bound != nil && !bound[pa.AttributeID]:
+			v.addf(productsFile, index, "product %q uses attribute %q which is not bound to its category", prod.Name, attr.Slug)
+		}
+
+		v.validateAttributeValue(index, prod, attr, pa)
+	}
+}
+
+func (v *validator) validateAttributeValue(index int, prod *Product, attr *Attribute, pa ProductAttribute) {
+	want, known := valueKindForType(attr.Type)
+	if !known {
+		return
+	}
+
+	kinds := pa.valueKinds()
+	if len(kinds) != 1 || kinds[0] != want {
+		v.addf(productsFile, index, "product %q attribute %q of type %q must set exactly %s, got [%s]",
+			prod.Name, attr.Slug, attr.Type, want, strings.Join(kinds, ", "))
+		return
+	}
+
+	slugs := pa.OptionSlugValues
+	if pa.OptionSlugValue != "" {
+		slugs = []string{pa.OptionSlugValue}
+	}
+	for _, slug := range slugs {
+		if !attr.hasOption(slug) {
+			v.addf(productsFile, index, "product %q attribute %q has unknown option slug %q", prod.Name, attr.Slug, slug)
+		}
+	}
+}
+
+// valueKindForType returns the ProductAttribute value field required by an attribute type.
+func valueKindForType(attrType string) (string, bool) {
+	switch strings.ToLower(attrType) {
+	case "single":
+		return "optionSlugValue", true
+	case "multiple":
+		return "optionSlugValues", true
+	case "range":
+		return "numericValue", true
+	case "boolean":
+		return "booleanValue", true
+	case "text":
+		return "textValue", true
+	default:
+		return "", false
+	}
+}
+
+// valueKinds returns the JSON names of the value fields set on the attribute.
+func (a ProductAttribute) valueKinds() []string {
+	var kinds []string
+	if a.OptionSlugValue != "" {
+		kinds = append(kinds, "optionSlugValue")
+	}
+	if len(a.OptionSlugValues) > 0 {
+		kinds = append(kinds, "optionSlugValues")
+	}
+	if a.NumericValue != nil {
+		kinds = append(kinds, "numericValue")
+	}
+	if a.TextValue != "" {
+		kinds = append(kinds, "textValue")
+	}
+	if a.BooleanValue != nil {
+		kinds = append(kinds, "booleanValue")
+	}
+	return kinds
+}
+
+func (a *Attribute) hasOption(slug string) bool {
+	for _, opt := range a.Options {
+		if opt.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
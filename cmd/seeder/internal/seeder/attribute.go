@@ -0,0 +1,154 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+func (s *Seeder) upsertAttributes(ctx context.Context) error {
+	key := func(attr data.Attribute) (string, string) { return attr.Name, attr.ID }
+	return forEach(ctx, s.concurrency, s.keepGoing, s.data.Attributes, tracked(s.results, kindAttribute, key, checkpointed(s.checkpoint, kindAttribute, key, s.upsertAttribute)))
+}
+
+func (s *Seeder) upsertAttribute(ctx context.Context, attr data.Attribute) (outcome, error) {
+	existing, err := s.getAttribute(ctx, attr.ID)
+	if err != nil {
+		return outcome{}, fmt.Errorf("failed to check attribute %s: %w", attr.Name, err)
+	}
+
+	if existing != nil {
+		warnImmutableAttributeFields(attr, existing)
+		if len(attributeDiff(attr, existing)) == 0 {
+			return outcome{id: attr.ID, action: ActionUnchanged}, nil
+		}
+		return s.updateWithConflictPolicy(ctx, "attribute", attr.Name, attr.ID, existing.Version,
+			func(ctx context.Context) (int64, bool, error) { return s.currentAttribute(ctx, attr) },
+			func(ctx context.Context, version int64) (string, error) { return s.updateAttribute(ctx, attr, version) })
+	}
+	return s.createAttribute(ctx, attr)
+}
+
+// warnImmutableAttributeFields reports seed changes to fields the catalog
+// cannot update; the attribute has to be recreated to apply them.
+func warnImmutableAttributeFields(attr data.Attribute, existing *catalogv1.Attribute) {
+	if diffs := attributeImmutableDiff(attr, existing); len(diffs) > 0 {
+		slog.Warn("Immutable attribute fields differ, not updating them",
+			"kind", kindAttribute, "id", attr.ID, "name", attr.Name, "conflicts", diffStrings(diffs))
+	}
+}
+
+func (s *Seeder) createAttribute(ctx context.Context, attr data.Attribute) (outcome, error) {
+	req := &catalogv1.CreateAttributeRequest{
+		Id:      &attr.ID,
+		Name:    attr.Name,
+		Slug:    attr.Slug,
+		Type:    toAttributeType(attr.Type),
+		Enabled: attr.Enabled,
+		Options: toAttributeOptionInputs(attr.Options),
+	}
+	if attr.Unit != "" {
+		req.Unit = &attr.Unit
+	}
+
+	resp, err := s.attributeClient.CreateAttribute(ctx, req)
+	if createdByRetry(err) {
+		return outcome{id: attr.ID, action: ActionCreated}, nil
+	}
+	if err != nil {
+		return outcome{}, fmt.Errorf("failed to create attribute %s: %w", attr.Name, err)
+	}
+
+	return outcome{id: resp.Attribute.GetId(), action: ActionCreated}, nil
+}
+
+func (s *Seeder) updateAttribute(ctx context.Context, attr data.Attribute, version int64) (string, error) {
+	req := &catalogv1.UpdateAttributeRequest{
+		Id:      attr.ID,
+		Name:    attr.Name,
+		Enabled: attr.Enabled,
+		Version: version,
+		Options: toAttributeOptionInputs(attr.Options),
+	}
+	if attr.Unit != "" {
+		req.Unit = &attr.Unit
+	}
+
+	resp, err := s.attributeClient.UpdateAttribute(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to update attribute %s: %w", attr.Name, err)
+	}
+
+	return resp.Attribute.GetId(), nil
+}
+
+// currentAttribute re-reads an existing attribute and returns its version and
+// whether it already matches the seed data.
+func (s *Seeder) currentAttribute(ctx context.Context, attr data.Attribute) (int64, bool, error) {
+	existing, err := s.getAttribute(ctx, attr.ID)
+	if err != nil {
+		return 0, false, err
+	}
+	if existing == nil {
+		return 0, false, fmt.Errorf("attribute %s no longer exists", attr.ID)
+	}
+	return existing.Version, len(attributeDiff(attr, existing)) == 0, nil
+}
+
+func (s *Seeder) getAttribute(ctx context.Context, id string) (*catalogv1.Attribute, error) {
+	resp, err := s.attributeClient.GetAttributeById(ctx, &catalogv1.GetAttributeByIdRequest{Id: id})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return resp.Attribute, nil
+}
+
+func toAttributeType(t string) catalogv1.AttributeType {
+	switch strings.ToUpper(t) {
+	case "SINGLE":
+		return catalogv1.AttributeType_ATTRIBUTE_TYPE_SINGLE
+	case "MULTIPLE":
+		return catalogv1.AttributeType_ATTRIBUTE_TYPE_MULTIPLE
+	case "RANGE":
+		return catalogv1.AttributeType_ATTRIBUTE_TYPE_RANGE
+	case "BOOLEAN":
+		return catalogv1.AttributeType_ATTRIBUTE_TYPE_BOOLEAN
+	case "TEXT":
+		return catalogv1.AttributeType_ATTRIBUTE_TYPE_TEXT
+	default:
+		return catalogv1.AttributeType_ATTRIBUTE_TYPE_UNSPECIFIED
+	}
+}
+
+func toAttributeOptionInputs(options []data.AttributeOption) []*catalogv1.AttributeOptionInput {
+	if len(options) == 0 {
+		return nil
+	}
+
+	inputs := make([]*catalogv1.AttributeOptionInput, len(options))
+	for i, opt := range options {
+		input := &catalogv1.AttributeOptionInput{
+			Name: opt.Name,
+			Slug: opt.Slug,
+		}
+		if opt.ColorCode != "" {
+			input.ColorCode = &opt.ColorCode
+		}
+		if opt.SortOrder > 0 {
+			so := int32(opt.SortOrder)
+			input.SortOrder = &so
+		}
+		inputs[i] = input
+	}
+	return inputs
+}
@@ -0,0 +1,71 @@
+package seeder
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+// fakeAttributeClient serves one stored attribute and counts updates.
+type fakeAttributeClient struct {
+	catalogv1.AttributeServiceClient
+	stored  *catalogv1.Attribute
+	updates int
+}
+
+func (c *fakeAttributeClient) GetAttributeById(_ context.Context, _ *catalogv1.GetAttributeByIdRequest, _ ...grpc.CallOption) (*catalogv1.GetAttributeByIdResponse, error) {
+	return &catalogv1.GetAttributeByIdResponse{Attribute: c.stored}, nil
+}
+
+func (c *fakeAttributeClient) UpdateAttribute(_ context.Context, req *catalogv1.UpdateAttributeRequest, _ ...grpc.CallOption) (*catalogv1.UpdateAttributeResponse, error) {
+	c.updates++
+	return &catalogv1.UpdateAttributeResponse{Attribute: &catalogv1.Attribute{Id: req.Id}}, nil
+}
+
+func TestUpsertAttributeSkipsNoOpUpdates(t *testing.T) {
+	desired := data.Attribute{
+		ID: "a1", Name: "Color", Slug: "color", Type: "single", Enabled: true,
+		Options: []data.AttributeOption{{Name: "Red", Slug: "red"}},
+	}
+	stored := func(mutate func(*catalogv1.Attribute)) *catalogv1.Attribute {
+		attr := &catalogv1.Attribute{
+			Id: "a1", Name: "Color", Slug: "color", Type: catalogv1.AttributeType_ATTRIBUTE_TYPE_SINGLE, Enabled: true,
+			Options: []*catalogv1.AttributeOption{{Name: "Red", Slug: "red", SortOrder: 1}},
+		}
+		mutate(attr)
+		return attr
+	}
+
+	tests := []struct {
+		name        string
+		stored      *catalogv1.Attribute
+		wantAction  Action
+		wantUpdates int
+	}{
+		{"up to date", stored(func(*catalogv1.Attribute) {}), ActionUnchanged, 0},
+		{"only immutable fields differ", stored(func(a *catalogv1.Attribute) { a.Slug = "colour" }), ActionUnchanged, 0},
+		{"renamed", stored(func(a *catalogv1.Attribute) { a.Name = "Colour" }), ActionUpdated, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeAttributeClient{stored: tt.stored}
+			s := &Seeder{attributeClient: client, conflictPolicy: conflictFail}
+
+			out, err := s.upsertAttribute(context.Background(), desired)
+			if err != nil {
+				t.Fatalf("upsertAttribute() error = %v", err)
+			}
+			if out.action != tt.wantAction {
+				t.Errorf("action = %s, want %s", out.action, tt.wantAction)
+			}
+			if client.updates != tt.wantUpdates {
+				t.Errorf("UpdateAttribute called %d times, want %d", client.updates, tt.wantUpdates)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+func (s *Seeder) upsertCategories(ctx context.Context) error {
+	key := func(cat data.Category) (string, string) { return cat.Name, cat.ID }
+	return forEach(ctx, s.concurrency, s.keepGoing, s.data.Categories, tracked(s.results, kindCategory, key, checkpointed(s.checkpoint, kindCategory, key, s.upsertCategory)))
+}
+
+func (s *Seeder) upsertCategory(ctx context.Context, cat data.Category) (outcome, error) {
+	if dep := s.failedCategoryDependency(cat); dep != "" {
+		s.results.block(kindCategory, cat.ID)
+		return outcome{id: cat.ID, action: ActionSkipped, reason: "depends on failed " + dep}, nil
+	}
+
+	existing, err := s.getCategory(ctx, cat.ID)
+	if err != nil {
+		return outcome{}, fmt.Errorf("failed to check category %s: %w", cat.Name, err)
+	}
+
+	if existing != nil {
+		if len(categoryDiff(cat, existing)) == 0 {
+			return outcome{id: cat.ID, action: ActionUnchanged}, nil
+		}
+		return s.updateWithConflictPolicy(ctx, "category", cat.Name, cat.ID, existing.Version,
+			func(ctx context.Context) (int64, bool, error) { return s.currentCategory(ctx, cat) },
+			func(ctx context.Context, version int64) (string, error) { return s.updateCategory(ctx, cat, version) })
+	}
+	return s.createCategory(ctx, cat)
+}
+
+func (s *Seeder) createCategory(ctx context.Context, cat data.Category) (outcome, error) {
+	req := &catalogv1.CreateCategoryRequest{
+		Id:         &cat.ID,
+		Name:       cat.Name,
+		Enabled:    cat.Enabled,
+		Attributes: toCategoryAttributeInputs(cat.Attributes),
+	}
+
+	resp, err := s.categoryClient.CreateCategory(ctx, req)
+	if createdByRetry(err) {
+		return outcome{id: cat.ID, action: ActionCreated}, nil
+	}
+	if err != nil {
+		return outcome{}, fmt.Errorf("failed to create category %s: %w", cat.Name, err)
+	}
+
+	return outcome{id: resp.Category.GetId(), action: ActionCreated}, nil
+}
+
+func (s *Seeder) updateCategory(ctx context.Context, cat data.Category, version int64) (string, error) {
+	req := &catalogv1.UpdateCategoryRequest{
+		Id:         cat.ID,
+		Name:       cat.Name,
+		Enabled:    cat.Enabled,
+		Version:    version,
+		Attributes: toCategoryAttributeInputs(cat.Attributes),
+	}
+
+	resp, err := s.categoryClient.UpdateCategory(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to update category %s: %w", cat.Name, err)
+	}
+
+	return resp.Category.GetId(), nil
+}
+
+// currentCategory re-reads an existing category and returns its version and
+// whether it already matches the seed data.
+func (s *Seeder) currentCategory(ctx context.Context, cat data.Category) (int64, bool, error) {
+	existing, err := s.getCategory(ctx, cat.ID)
+	if err != nil {
+		return 0, false, err
+	}
+	if existing == nil {
+		return 0, false, fmt.Errorf("category %s no longer exists", cat.ID)
+	}
+	return existing.Version, len(categoryDiff(cat, existing)) == 0, nil
+}
+
+func (s *Seeder) getCategory(ctx context.Context, id string) (*catalogv1.Category, error) {
+	resp, err := s.categoryClient.GetCategoryById(ctx, &catalogv1.GetCategoryByIdRequest{Id: id})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return resp.Category, nil
+}
+
+func toCategoryAttributeInputs(attrs []data.CategoryAttribute) []*catalogv1.CategoryAttributeInput {
+	result := make([]*catalogv1.CategoryAttributeInput, 0, len(attrs))
+	for _, a := range attrs {
+		input := &catalogv1.CategoryAttributeInput{
+			AttributeId: a.AttributeID,
+			Role:        toCategoryAttributeRole(a.Role),
+			Filterable:  a.Filterable,
+			Searchable:  a.Searchable,
+		}
+		if a.SortOrder > 0 {
+			so := int32(a.SortOrder)
+			input.SortOrder = &so
+		}
+		result = append(result, input)
+	}
+	return result
+}
+
+func toCategoryAttributeRole(role string) catalogv1.CategoryAttributeRole {
+	switch strings.ToUpper(role) {
+	case "VARIANT":
+		return catalogv1.CategoryAttributeRole_CATEGORY_ATTRIBUTE_ROLE_VARIANT
+	case "SPECIFICATION":
+		return catalogv1.CategoryAttributeRole_CATEGORY_ATTRIBUTE_ROLE_SPECIFICATION
+	default:
+		return catalogv1.CategoryAttributeRole_CATEGORY_ATTRIBUTE_ROLE_UNSPECIFIED
+	}
+}
@@ -0,0 +1,230 @@
+package seeder
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpoint records the progress of a run so an interrupted run can be
+// resumed with --resume. The file is an append-only log of JSON lines: a
+// header naming the tenant and dataset, then one line per completed entity
+// and per uploaded or deleted image. It is removed once a run finishes
+// without failures.
+type checkpoint struct {
+	mu       sync.Mutex
+	path     string
+	header   checkpointEntry
+	started  bool              // the file holds this run's header
+	uploaded map[string]string // upload dedupe key -> image ID
+	resumed  map[string]bool   // kind + "/" + ID completed by the resumed run
+}
+
+// checkpointEntry is one line of the checkpoint file: the header (Tenant and
+// DatasetHash), a completed entity (Kind and ID), an uploaded image (Image
+// key and ID) or a deleted image (Dropped and ID).
+type checkpointEntry struct {
+	Tenant      string `json:"tenant,omitempty"`
+	DatasetHash string `json:"datasetHash,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Image       string `json:"image,omitempty"`
+	Dropped     bool   `json:"dropped,omitempty"`
+	ID          string `json:"id,omitempty"`
+}
+
+// newCheckpoint prepares the checkpoint file at path for a run of the dataset
+// with the given hash; when path is a directory, each tenant gets its own
+// file in it. With resume, progress from an earlier run of the same tenant
+// and dataset is picked up; otherwise the run starts from scratch.
+func newCheckpoint(path, tenant, datasetHash string, resume bool) (*checkpoint, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, cmp.Or(tenant, "default")+".checkpoint")
+	}
+	c := &checkpoint{
+		path:     path,
+		header:   checkpointEntry{Tenant: tenant, DatasetHash: datasetHash},
+		uploaded: make(map[string]string),
+		resumed:  make(map[string]bool),
+	}
+	if path == "" || !resume {
+		return c, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Warn("No checkpoint found, starting from scratch", "path", path)
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	entries, size, err := parseCheckpoint(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if len(entries) == 0 || entries[0] != c.header {
+		slog.Warn("Checkpoint is for a different tenant or dataset, starting from scratch", "path", path)
+		return c, nil
+	}
+
+	for _, e := range entries[1:] {
+		switch {
+		case e.Kind != "":
+			c.resumed[e.Kind+"/"+e.ID] = true
+		case e.Image != "":
+			c.uploaded[e.Image] = e.ID
+		case e.Dropped:
+			deleteImageID(c.uploaded, e.ID)
+		}
+	}
+	// Drop a line cut off by a killed run so appends start on a fresh line.
+	if size < len(raw) {
+		if err := os.Truncate(path, int64(size)); err != nil {
+			return nil, fmt.Errorf("failed to repair checkpoint: %w", err)
+		}
+	}
+	c.started = true
+	slog.Info("Resuming from checkpoint", "path", path, "entities", len(c.resumed), "images", len(c.uploaded))
+	return c, nil
+}
+
+// parseCheckpoint decodes the lines of a checkpoint file and returns the size
+// of the complete ones. A last line without a newline was cut off by a killed
+// run and is ignored.
+func parseCheckpoint(raw []byte) (entries []checkpointEntry, size int, err error) {
+	size = bytes.LastIndexByte(raw, '\n') + 1
+	lines := bytes.Split(raw[:size], []byte("\n"))
+	for i, line := range lines[:len(lines)-1] {
+		var e checkpointEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, 0, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, size, nil
+}
+
+func deleteImageID(images map[string]string, id string) {
+	for key, cached := range images {
+		if cached == id {
+			delete(images, key)
+		}
+	}
+}
+
+func (c *checkpoint) enabled() bool {
+	return c.path != ""
+}
+
+// done reports whether the resumed run already completed the entity.
+func (c *checkpoint) done(kind, id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resumed[kind+"/"+id]
+}
+
+// images returns the images uploaded by the resumed run.
+func (c *checkpoint) images() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.uploaded)
+}
+
+func (c *checkpoint) complete(kind, id string) error {
+	return c.append(checkpointEntry{Kind: kind, ID: id})
+}
+
+func (c *checkpoint) addImage(key, id string) error {
+	return c.append(checkpointEntry{Image: key, ID: id})
+}
+
+// dropImage forgets a deleted image so a resumed run does not try to reuse it.
+func (c *checkpoint) dropImage(id string) error {
+	return c.append(checkpointEntry{Dropped: true, ID: id})
+}
+
+// append writes entry as one line in a single write, so a run killed
+// mid-write leaves at most a partial last line, which resuming ignores. The
+// first write of a run that is not resuming replaces the file with a header.
+func (c *checkpoint) append(entry checkpointEntry) error {
+	if !c.enabled() {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var buf bytes.Buffer
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !c.started {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		_ = json.NewEncoder(&buf).Encode(c.header)
+	}
+	_ = json.NewEncoder(&buf).Encode(entry)
+
+	f, err := os.OpenFile(c.path, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	c.started = true
+	if entry.Image != "" {
+		c.uploaded[entry.Image] = entry.ID
+	}
+	if entry.Dropped {
+		deleteImageID(c.uploaded, entry.ID)
+	}
+	return nil
+}
+
+// remove deletes the checkpoint file after a run that needs no resuming.
+func (c *checkpoint) remove() error {
+	if !c.enabled() {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointed wraps an upsert so entities completed by the resumed run are
+// skipped and newly completed ones are recorded. key returns the entity's
+// name and seed ID.
+func checkpointed[T any](c *checkpoint, kind string, key func(T) (string, string), fn func(context.Context, T) (outcome, error)) func(context.Context, T) (outcome, error) {
+	return func(ctx context.Context, item T) (outcome, error) {
+		_, id := key(item)
+		if c.done(kind, id) {
+			return outcome{id: id, action: ActionSkipped, reason: "already done in checkpoint"}, nil
+		}
+
+		out, err := fn(ctx, item)
+		if err != nil {
+			return out, err
+		}
+		// Skipped entities are not done: a dependency or a concurrent edit
+		// kept them from being written.
+		if out.action != ActionSkipped {
+			if err := c.complete(kind, id); err != nil {
+				slog.Warn("Failed to update checkpoint", "error", err)
+			}
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,137 @@
+package seeder
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCheckpoint records a run of acme/v1 that completed one product,
+// uploaded two images and deleted one of them.
+func writeCheckpoint(t *testing.T, path string) {
+	t.Helper()
+	c, err := newCheckpoint(path, "acme", "v1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range []error{
+		c.complete(kindProduct, "p1"),
+		c.addImage("p1:aa:main", "img1"),
+		c.addImage("p1:bb:gallery", "img2"),
+		c.dropImage("img2"),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	tests := []struct {
+		name       string
+		tenant     string
+		dataset    string
+		resume     bool
+		corrupt    func(t *testing.T, path string)
+		wantDone   bool
+		wantImages map[string]string
+	}{
+		{
+			name: "round trip", tenant: "acme", dataset: "v1", resume: true,
+			wantDone: true, wantImages: map[string]string{"p1:aa:main": "img1"},
+		},
+		{
+			name: "truncated last line", tenant: "acme", dataset: "v1", resume: true,
+			corrupt: func(t *testing.T, path string) {
+				f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				if _, err := f.WriteString(`{"kind":"product","i`); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantDone: true, wantImages: map[string]string{"p1:aa:main": "img1"},
+		},
+		{
+			name: "other dataset", tenant: "acme", dataset: "v2", resume: true,
+			wantImages: map[string]string{},
+		},
+		{
+			name: "other tenant", tenant: "globex", dataset: "v1", resume: true,
+			wantImages: map[string]string{},
+		},
+		{
+			name: "without resume", tenant: "acme", dataset: "v1",
+			wantImages: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "seed.checkpoint")
+			writeCheckpoint(t, path)
+			if tt.corrupt != nil {
+				tt.corrupt(t, path)
+			}
+
+			c, err := newCheckpoint(path, tt.tenant, tt.dataset, tt.resume)
+			if err != nil {
+				t.Fatalf("newCheckpoint() error = %v", err)
+			}
+			if got := c.done(kindProduct, "p1"); got != tt.wantDone {
+				t.Errorf("done(product, p1) = %v, want %v", got, tt.wantDone)
+			}
+			if got := c.images(); !maps.Equal(got, tt.wantImages) {
+				t.Errorf("images() = %v, want %v", got, tt.wantImages)
+			}
+
+			// Progress appended after resuming must survive the next resume.
+			if err := c.complete(kindCategory, "c1"); err != nil {
+				t.Fatal(err)
+			}
+			again, err := newCheckpoint(path, tt.tenant, tt.dataset, true)
+			if err != nil {
+				t.Fatalf("newCheckpoint() after append error = %v", err)
+			}
+			if !again.done(kindCategory, "c1") {
+				t.Error("done(category, c1) = false after append, want true")
+			}
+			if got := again.done(kindProduct, "p1"); got != tt.wantDone {
+				t.Errorf("done(product, p1) after append = %v, want %v", got, tt.wantDone)
+			}
+		})
+	}
+}
+
+func TestCheckpointDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeCheckpoint(t, dir)
+
+	if _, err := os.Stat(filepath.Join(dir, "acme.checkpoint")); err != nil {
+		t.Fatalf("tenant checkpoint not written: %v", err)
+	}
+	c, err := newCheckpoint(dir, "acme", "v1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.done(kindProduct, "p1") {
+		t.Error("done(product, p1) = false, want true")
+	}
+	other, err := newCheckpoint(dir, "globex", "v1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.done(kindProduct, "p1") {
+		t.Error("another tenant resumed acme's checkpoint")
+	}
+
+	if err := c.remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "acme.checkpoint")); !os.IsNotExist(err) {
+		t.Errorf("checkpoint still present after remove: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Conflict policies for updates rejected because the entity's version changed
+// between the read and the write.
+const (
+	conflictFail    = "fail"
+	conflictRefetch = "refetch-and-retry"
+	conflictSkip    = "skip"
+)
+
+// maxConflictRetries bounds refetch-and-retry for entities edited continuously.
+const maxConflictRetries = 3
+
+func validateConflictPolicy(policy string) error {
+	switch policy {
+	case conflictFail, conflictRefetch, conflictSkip:
+		return nil
+	default:
+		return fmt.Errorf("invalid conflict policy %q: expected %s, %s or %s", policy, conflictFail, conflictRefetch, conflictSkip)
+	}
+}
+
+// conflictStats counts version conflicts across the run.
+type conflictStats struct {
+	total       atomic.Int64
+	overwritten atomic.Int64
+	skipped     atomic.Int64
+}
+
+// isConflict reports whether err is an optimistic-concurrency rejection, which
+// the catalog service returns as Aborted. FailedPrecondition is a genuine
+// failure (e.g. a disabled referenced category) and must not be skipped.
+func isConflict(err error) bool {
+	return status.Code(err) == codes.Aborted
+}
+
+// updateWithConflictPolicy calls update with version and applies the conflict
+// policy when the write loses a race: fail returns the error, skip keeps the
+// concurrent edit, and refetch-and-retry reads the latest version and
+// overwrites it. refetch returns the current version of the entity and whether
+// it already matches the seed data.
+func (s *Seeder) updateWithConflictPolicy(ctx context.Context, kind, name, id string, version int64,
+	refetch func(context.Context) (int64, bool, error), update func(context.Context, int64) (string, error),
+) (outcome, error) {
+	// The concurrent edit counts as overwritten only once an update built on
+	// the refetched version has gone through.
+	refetched := false
+	updated := func(id string) (outcome, error) {
+		if refetched {
+			s.conflicts.overwritten.Add(1)
+		}
+		return outcome{id: id, action: ActionUpdated}, nil
+	}
+	for attempt := 0; ; attempt++ {
+		updatedID, err := update(ctx, version)
+		if err == nil {
+			return updated(updatedID)
+		}
+		if !isConflict(err) {
+			return outcome{}, err
+		}
+		// A retried update conflicts with itself when an earlier attempt that
+		// reported a transient failure was applied.
+		if wasRetried(err) {
+			if _, current, err := refetch(ctx); err == nil && current {
+				return updated(id)
+			}
+		}
+		s.conflicts.total.Add(1)
+
+		switch {
+		case s.conflictPolicy == conflictSkip:
+			s.conflicts.skipped.Add(1)
+			return outcome{id: id, action: ActionSkipped, reason: "modified concurrently, keeping the current version"}, nil
+		case s.conflictPolicy == conflictRefetch && attempt < maxConflictRetries:
+			version, _, err = refetch(ctx)
+			if err != nil {
+				return outcome{}, fmt.Errorf("failed to refetch %s %s after conflict: %w", kind, name, err)
+			}
+			refetched = true
+			slog.Info("Version conflict, retrying", "kind", kind, "id", id, "name", name, "version", version)
+		default:
+			return outcome{}, err
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"aborted", status.Error(codes.Aborted, "version mismatch"), true},
+		{"wrapped aborted", fmt.Errorf("failed to update product: %w", status.Error(codes.Aborted, "")), true},
+		{"failed precondition", status.Error(codes.FailedPrecondition, "category disabled"), false},
+		{"already exists", status.Error(codes.AlreadyExists, "duplicate slug"), false},
+		{"non-gRPC error", errors.New("boom"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConflict(tt.err); got != tt.want {
+				t.Errorf("isConflict(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateWithConflictPolicyRetriedUpdate(t *testing.T) {
+	aborted := status.Error(codes.Aborted, "version mismatch")
+
+	tests := []struct {
+		name       string
+		updateErr  error
+		current    bool
+		wantAction Action
+		wantErr    bool
+		wantTotal  int64
+	}{
+		{
+			name:      "retried update already applied",
+			updateErr: &retriedError{err: aborted}, current: true,
+			wantAction: ActionUpdated,
+		},
+		{
+			name:      "retried update lost to another writer",
+			updateErr: &retriedError{err: aborted},
+			wantErr:   true, wantTotal: 1,
+		},
+		{
+			name:      "conflict on the first attempt",
+			updateErr: aborted, current: true,
+			wantErr: true, wantTotal: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Seeder{conflictPolicy: conflictFail}
+			out, err := s.updateWithConflictPolicy(context.Background(), "product", "Laptop", "p1", 1,
+				func(context.Context) (int64, bool, error) { return 2, tt.current, nil },
+				func(context.Context, int64) (string, error) { return "", tt.updateErr })
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("updateWithConflictPolicy() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && out.action != tt.wantAction {
+				t.Errorf("action = %s, want %s", out.action, tt.wantAction)
+			}
+			if got := s.conflicts.total.Load(); got != tt.wantTotal {
+				t.Errorf("conflicts = %d, want %d", got, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestUpdateWithConflictPolicyRefetchCounts(t *testing.T) {
+	aborted := status.Error(codes.Aborted, "version mismatch")
+
+	tests := []struct {
+		name            string
+		errs            []error // returned by successive updates, then nil
+		wantErr         bool
+		wantTotal       int64
+		wantOverwritten int64
+	}{
+		{name: "no conflict"},
+		{name: "overwritten after one conflict", errs: []error{aborted}, wantTotal: 1, wantOverwritten: 1},
+		{name: "overwritten after two conflicts", errs: []error{aborted, aborted}, wantTotal: 2, wantOverwritten: 1},
+		{
+			name:    "retry fails after refetch",
+			errs:    []error{aborted, status.Error(codes.Unavailable, "")},
+			wantErr: true, wantTotal: 1,
+		},
+		{
+			name:    "retries exhausted",
+			errs:    []error{aborted, aborted, aborted, aborted},
+			wantErr: true, wantTotal: maxConflictRetries + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Seeder{conflictPolicy: conflictRefetch}
+			calls := 0
+			_, err := s.updateWithConflictPolicy(context.Background(), "product", "Laptop", "p1", 1,
+				func(context.Context) (int64, bool, error) { return int64(calls) + 1, false, nil },
+				func(context.Context, int64) (string, error) {
+					defer func() { calls++ }()
+					if calls < len(tt.errs) {
+						return "", tt.errs[calls]
+					}
+					return "p1", nil
+				})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("updateWithConflictPolicy() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := s.conflicts.total.Load(); got != tt.wantTotal {
+				t.Errorf("conflicts = %d, want %d", got, tt.wantTotal)
+			}
+			if got := s.conflicts.overwritten.Load(); got != tt.wantOverwritten {
+				t.Errorf("overwritten = %d, want %d", got, tt.wantOverwritten)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package seeder
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+const absent = "<none>"
+
+// fieldDiff is a single field-level difference between the current and desired entity.
+type fieldDiff struct {
+	Field string
+	From  string
+	To    string
+}
+
+func (d fieldDiff) String() string {
+	return fmt.Sprintf("%s: %s → %s", d.Field, d.From, d.To)
+}
+
+type differ struct {
+	diffs []fieldDiff
+}
+
+func (d *differ) compare(field string, from, to any) {
+	if from != to {
+		d.diffs = append(d.diffs, fieldDiff{Field: field, From: formatValue(from), To: formatValue(to)})
+	}
+}
+
+func (d *differ) add(field, from, to string) {
+	d.diffs = append(d.diffs, fieldDiff{Field: field, From: from, To: to})
+}
+
+func diffStrings(diffs []fieldDiff) []string {
+	out := make([]string, len(diffs))
+	for i, d := range diffs {
+		out[i] = d.String()
+	}
+	return out
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// attributeImmutableDiff returns the differences UpdateAttribute cannot apply.
+// They are reported as conflicts, never as updates, so runs still converge.
+func attributeImmutableDiff(desired data.Attribute, existing *catalogv1.Attribute) []fieldDiff {
+	d := &differ{}
+	d.compare("slug", existing.GetSlug(), desired.Slug)
+	d.compare("type", existing.GetType(), toAttributeType(desired.Type))
+	return d.diffs
+}
+
+func attributeDiff(desired data.Attribute, existing *catalogv1.Attribute) []fieldDiff {
+	d := &differ{}
+	d.compare("name", existing.GetName(), desired.Name)
+	d.compare("unit", existing.GetUnit(), desired.Unit)
+	d.compare("enabled", existing.GetEnabled(), desired.Enabled)
+
+	current := make(map[string]*catalogv1.AttributeOption, len(existing.GetOptions()))
+	for _, opt := range existing.GetOptions() {
+		current[opt.GetSlug()] = opt
+	}
+	for _, opt := range desired.Options {
+		field := fmt.Sprintf("options[%s]", opt.Slug)
+		cur, ok := current[opt.Slug]
+		if !ok {
+			d.add(field, absent, strconv.Quote(opt.Name))
+			continue
+		}
+		delete(current, opt.Slug)
+		d.compare(field+".name", cur.GetName(), opt.Name)
+		d.compare(field+".colorCode", cur.GetColorCode(), opt.ColorCode)
+		if opt.SortOrder > 0 {
+			d.compare(field+".sortOrder", cur.GetSortOrder(), int32(opt.SortOrder))
+		}
+	}
+	for _, slug := range sortedKeys(current) {
+		d.add(fmt.Sprintf("options[%s]", slug), strconv.Quote(current[slug].GetName()), absent)
+	}
+
+	return d.diffs
+}
+
+func categoryDiff(desired data.Category, existing *catalogv1.Category) []fieldDiff {
+	d := &differ{}
+	d.compare("name", existing.GetName(), desired.Name)
+	d.compare("enabled", existing.GetEnabled(), desired.Enabled)
+
+	current := make(map[string]*catalogv1.CategoryAttribute, len(existing.GetAttributes()))
+	for _, a := range existing.GetAttributes() {
+		current[a.GetAttributeId()] = a
+	}
+	for _, a := range desired.Attributes {
+		field := fmt.Sprintf("attributes[%s]", a.AttributeID)
+		cur, ok := current[a.AttributeID]
+		if !ok {
+			d.add(field, absent, strings.ToLower(a.Role))
+			continue
+		}
+		delete(current, a.AttributeID)
+		d.compare(field+".role", cur.GetRole(), toCategoryAttributeRole(a.Role))
+		if a.SortOrder > 0 {
+			d.compare(field+".sortOrder", cur.GetSortOrder(), int32(a.SortOrder))
+		}
+		d.compare(field+".filterable", cur.GetFilterable(), a.Filterable)
+		d.compare(field+".searchable", cur.GetSearchable(), a.Searchable)
+	}
+	for _, id := range sortedKeys(current) {
+		d.add(fmt.Sprintf("attributes[%s]", id), current[id].GetRole().String(), absent)
+	}
+
+	return d.diffs
+}
+
+// productDiff compares everything except the image, which is only known after upload.
+// enabled is the effective flag (a product without an image is forced to disabled).
+func productDiff(desired data.Product, enabled bool, existing *catalogv1.Product) []fieldDiff {
+	d := &differ{}
+	d.compare("name", existing.GetName(), desired.Name)
+	d.compare("description", existing.GetDescription(), desired.Description)
+	d.compare("price", existing.GetPrice(), desired.Price)
+	d.compare("quantity", existing.GetQuantity(), int32(desired.Quantity))
+	d.compare("categoryId", existing.GetCategoryId(), desired.CategoryID)
+	d.compare("enabled", existing.GetEnabled(), enabled)
+
+	current := make(map[string]attributeValue, len(existing.GetAttributes()))
+	for _, a := range existing.GetAttributes() {
+		current[a.GetAttributeId()] = a
+	}
+	for _, a := range desired.Attributes {
+		field := fmt.Sprintf("attributes[%s]", a.AttributeID)
+		want := formatDesiredValue(a)
+		cur, ok := current[a.AttributeID]
+		if !ok {
+			d.add(field, absent, want)
+			continue
+		}
+		delete(current, a.AttributeID)
+		if got := formatExistingValue(cur, a); got != want {
+			d.add(field, got, want)
+		}
+	}
+	for _, id := range sortedKeys(current) {
+		d.add(fmt.Sprintf("attributes[%s]", id), "<set>", absent)
+	}
+
+	return d.diffs
+}
+
+// attributeValue is the read side of a product attribute value.
+type attributeValue interface {
+	GetAttributeId() string
+	GetOptionSlugValue() string
+	GetOptionSlugValues() *catalogv1.StringList
+	GetNumericValue() float64
+	GetTextValue() string
+	GetBooleanValue() bool
+}
+
+func formatDesiredValue(a data.ProductAttribute) string {
+	switch {
+	case a.OptionSlugValue != "":
+		return strconv.Quote(a.OptionSlugValue)
+	case len(a.OptionSlugValues) > 0:
+		return formatSlugs(a.OptionSlugValues)
+	case a.NumericValue != nil:
+		return strconv.FormatFloat(*a.NumericValue, 'g', -1, 64)
+	case a.TextValue != "":
+		return strconv.Quote(a.TextValue)
+	case a.BooleanValue != nil:
+		return strconv.FormatBool(*a.BooleanValue)
+	default:
+		return absent
+	}
+}
+
+// formatExistingValue reads the same value kind that the desired attribute sets,
+// since proto getters cannot tell an unset oneof from a zero value.
+func formatExistingValue(v attributeValue, like data.ProductAttribute) string {
+	switch {
+	case like.OptionSlugValue != "":
+		return strconv.Quote(v.GetOptionSlugValue())
+	case len(like.OptionSlugValues) > 0:
+		return formatSlugs(v.GetOptionSlugValues().GetValues())
+	case like.NumericValue != nil:
+		return strconv.FormatFloat(v.GetNumericValue(), 'g', -1, 64)
+	case like.TextValue != "":
+		return strconv.Quote(v.GetTextValue())
+	case like.BooleanValue != nil:
+		return strconv.FormatBool(v.GetBooleanValue())
+	default:
+		return absent
+	}
+}
+
+// formatSlugs renders a multi-select value order-insensitively.
+func formatSlugs(slugs []string) string {
+	sorted := slices.Sorted(slices.Values(slugs))
+	return "[" + strings.Join(sorted, ", ") + "]"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,204 @@
+package seeder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+func fields(diffs []fieldDiff) []string {
+	out := make([]string, len(diffs))
+	for i, d := range diffs {
+		out[i] = d.Field
+	}
+	return out
+}
+
+func TestAttributeDiff(t *testing.T) {
+	desired := data.Attribute{
+		Name: "Color", Slug: "color", Type: "single", Enabled: true,
+		Options: []data.AttributeOption{{Name: "Red", Slug: "red"}, {Name: "Blue", Slug: "blue", SortOrder: 2}},
+	}
+	existing := func(mutate func(*catalogv1.Attribute)) *catalogv1.Attribute {
+		attr := &catalogv1.Attribute{
+			Name: "Color", Slug: "color", Type: catalogv1.AttributeType_ATTRIBUTE_TYPE_SINGLE, Enabled: true,
+			Options: []*catalogv1.AttributeOption{{Name: "Red", Slug: "red", SortOrder: 1}, {Name: "Blue", Slug: "blue", SortOrder: 2}},
+		}
+		if mutate != nil {
+			mutate(attr)
+		}
+		return attr
+	}
+
+	tests := []struct {
+		name     string
+		existing *catalogv1.Attribute
+		want     []string
+	}{
+		{"unchanged, server-assigned sort order ignored", existing(nil), []string{}},
+		{"renamed", existing(func(a *catalogv1.Attribute) { a.Name = "Colour" }), []string{"name"}},
+		{"disabled", existing(func(a *catalogv1.Attribute) { a.Enabled = false }), []string{"enabled"}},
+		{
+			"option added and removed",
+			existing(func(a *catalogv1.Attribute) { a.Options[1] = &catalogv1.AttributeOption{Name: "Green", Slug: "green"} }),
+			[]string{"options[blue]", "options[green]"},
+		},
+		{
+			"explicit sort order changed",
+			existing(func(a *catalogv1.Attribute) { a.Options[1].SortOrder = 5 }),
+			[]string{"options[blue].sortOrder"},
+		},
+		{"slug change is not an update", existing(func(a *catalogv1.Attribute) { a.Slug = "colour" }), []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(attributeDiff(desired, tt.existing)); !slices.Equal(got, tt.want) {
+				t.Errorf("attributeDiff() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttributeImmutableDiff(t *testing.T) {
+	desired := data.Attribute{Slug: "color", Type: "single"}
+	existing := &catalogv1.Attribute{Slug: "colour", Type: catalogv1.AttributeType_ATTRIBUTE_TYPE_MULTIPLE}
+
+	want := []string{"slug", "type"}
+	if got := fields(attributeImmutableDiff(desired, existing)); !slices.Equal(got, want) {
+		t.Errorf("attributeImmutableDiff() fields = %v, want %v", got, want)
+	}
+}
+
+func TestCategoryDiff(t *testing.T) {
+	desired := data.Category{
+		Name: "Phones", Enabled: true,
+		Attributes: []data.CategoryAttribute{{AttributeID: "a1", Role: "variant", Filterable: true}},
+	}
+	existing := func(mutate func(*catalogv1.Category)) *catalogv1.Category {
+		cat := &catalogv1.Category{
+			Name: "Phones", Enabled: true,
+			Attributes: []*catalogv1.CategoryAttribute{{
+				AttributeId: "a1", Role: catalogv1.CategoryAttributeRole_CATEGORY_ATTRIBUTE_ROLE_VARIANT, SortOrder: 3, Filterable: true,
+			}},
+		}
+		if mutate != nil {
+			mutate(cat)
+		}
+		return cat
+	}
+
+	tests := []struct {
+		name     string
+		existing *catalogv1.Category
+		want     []string
+	}{
+		{"unchanged", existing(nil), []string{}},
+		{
+			"role changed",
+			existing(func(c *catalogv1.Category) {
+				c.Attributes[0].Role = catalogv1.CategoryAttributeRole_CATEGORY_ATTRIBUTE_ROLE_SPECIFICATION
+			}),
+			[]string{"attributes[a1].role"},
+		},
+		{
+			"extra attribute on the server",
+			existing(func(c *catalogv1.Category) {
+				c.Attributes = append(c.Attributes, &catalogv1.CategoryAttribute{AttributeId: "a2"})
+			}),
+			[]string{"attributes[a2]"},
+		},
+		{"attribute missing", existing(func(c *catalogv1.Category) { c.Attributes = nil }), []string{"attributes[a1]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(categoryDiff(desired, tt.existing)); !slices.Equal(got, tt.want) {
+				t.Errorf("categoryDiff() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductChanges(t *testing.T) {
+	assets := t.TempDir()
+	if err := os.WriteFile(filepath.Join(assets, "p1.jpg"), []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	size := 64.0
+	str := func(s string) *string { return &s }
+	desired := func(id string) data.Product {
+		return data.Product{
+			ID: id, Name: "Phone", Price: 10, Quantity: 2, CategoryID: "c1", Enabled: true,
+			Attributes: []data.ProductAttribute{
+				{AttributeID: "a1", OptionSlugValues: []string{"red", "blue"}},
+				{AttributeID: "a2", NumericValue: &size},
+			},
+		}
+	}
+	existing := func(enabled bool, imageID string, mutate func(*catalogv1.Product)) *catalogv1.Product {
+		prod := &catalogv1.Product{
+			Name: "Phone", Price: 10, Quantity: 2, CategoryId: str("c1"), Enabled: enabled,
+			Attributes: []*catalogv1.AttributeValue{
+				{AttributeId: "a1", Value: &catalogv1.AttributeValue_OptionSlugValues{
+					OptionSlugValues: &catalogv1.StringList{Values: []string{"blue", "red"}},
+				}},
+				{AttributeId: "a2", Value: &catalogv1.AttributeValue_NumericValue{NumericValue: 64}},
+			},
+		}
+		if imageID != "" {
+			prod.ImageId = str(imageID)
+		}
+		if mutate != nil {
+			mutate(prod)
+		}
+		return prod
+	}
+
+	tests := []struct {
+		name     string
+		desired  data.Product
+		existing *catalogv1.Product
+		want     []string
+	}{
+		{"unchanged", desired("p1"), existing(true, "img1", nil), []string{}},
+		{"image missing on the server", desired("p1"), existing(true, "", nil), []string{"image"}},
+		{"no image file, forced disabled", desired("p2"), existing(false, "", nil), []string{}},
+		{"no image file but one attached", desired("p2"), existing(false, "img1", nil), []string{"image"}},
+		{
+			"fields and attribute values changed",
+			desired("p1"),
+			existing(true, "img1", func(p *catalogv1.Product) {
+				p.Price = 12
+				p.Attributes[1].Value = &catalogv1.AttributeValue_NumericValue{NumericValue: 128}
+			}),
+			[]string{"price", "attributes[a2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Seeder{assetsDir: assets}
+
+			diffs, err := s.productChanges(context.Background(), tt.desired, tt.existing)
+			if err != nil {
+				t.Fatalf("productChanges() error = %v", err)
+			}
+			if got := fields(diffs); !slices.Equal(got, tt.want) {
+				t.Errorf("productChanges() fields = %v, want %v", got, tt.want)
+			}
+			unchanged, err := s.productUnchanged(context.Background(), tt.desired, tt.existing)
+			if err != nil {
+				t.Fatalf("productUnchanged() error = %v", err)
+			}
+			if unchanged != (len(tt.want) == 0) {
+				t.Errorf("productUnchanged() = %v, want %v", unchanged, len(tt.want) == 0)
+			}
+		})
+	}
+}
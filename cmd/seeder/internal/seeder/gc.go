@@ -0,0 +1,155 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+)
+
+// draftOwnerPrefix marks draft owner IDs created by the seeder.
+const draftOwnerPrefix = "seed_"
+
+// cleanupTimeout bounds orphan cleanup, which also runs after the run context is cancelled.
+const cleanupTimeout = 30 * time.Second
+
+// uploadTracker records the images uploaded in this run and which of them
+// were attached to a successfully written product.
+type uploadTracker struct {
+	mu       sync.Mutex
+	uploaded map[string]string // imageID -> asset file
+	attached map[string]bool
+}
+
+func newUploadTracker() *uploadTracker {
+	return &uploadTracker{
+		uploaded: make(map[string]string),
+		attached: make(map[string]bool),
+	}
+}
+
+func (t *uploadTracker) add(id, file string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.uploaded[id] = file
+}
+
+func (t *uploadTracker) attach(ids ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range ids {
+		t.attached[id] = true
+	}
+}
+
+// orphans returns the images uploaded in this run that no product claimed.
+func (t *uploadTracker) orphans() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	orphans := make(map[string]string)
+	for id, file := range t.uploaded {
+		if !t.attached[id] {
+			orphans[id] = file
+		}
+	}
+	return orphans
+}
+
+// cleanupOrphanedImages deletes images uploaded in this run whose product
+// write failed.
+func (s *Seeder) cleanupOrphanedImages(ctx context.Context) {
+	orphans := s.uploads.orphans()
+	if len(orphans) == 0 {
+		return
+	}
+	if ctx.Err() != nil && s.checkpoint.enabled() {
+		slog.Info("Keeping uploaded images in the checkpoint for --resume", "count", len(orphans))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+
+	slog.Info("Deleting images orphaned by failed product writes", "count", len(orphans))
+	for _, id := range slices.Sorted(maps.Keys(orphans)) {
+		if err := s.deleteImage(ctx, id); err != nil {
+			slog.Warn("Failed to delete orphaned image", "kind", kindImage, "id", id, "name", orphans[id], "error", err)
+			continue
+		}
+		s.images.drop(id)
+		if err := s.checkpoint.dropImage(id); err != nil {
+			slog.Warn("Failed to update checkpoint", "error", err)
+		}
+		slog.Info("Deleted orphaned image", "kind", kindImage, "id", id, "name", orphans[id], "action", "deleted")
+	}
+}
+
+// GCImages deletes stale seed draft images: images still owned by a seed_*
+// draft, older than minAge and not the source of a product copy recorded in
+// the cache, which later runs reuse. The image service has no List RPC, so the
+// image cache file is the inventory; images uploaded without --image-cache
+// cannot be found.
+func (s *Seeder) GCImages(ctx context.Context, minAge time.Duration) error {
+	if s.images.path == "" {
+		return fmt.Errorf("gc-images requires --image-cache to know which images were uploaded")
+	}
+	candidates := s.images.persistedEntries()
+	slog.Info("Checking cached images", "count", len(candidates))
+
+	deleted := 0
+	for _, hash := range slices.Sorted(maps.Keys(candidates)) {
+		id := candidates[hash]
+		if s.images.referenced(id) {
+			continue
+		}
+		resp, err := s.imageClient.GetImage(ctx, &imagev1.GetImageRequest{Id: id})
+		if err != nil {
+			if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+				s.images.forget(hash)
+				continue
+			}
+			return fmt.Errorf("failed to get image %s: %w", id, err)
+		}
+
+		img := resp.GetImage()
+		if img.GetStatus() == imagev1.ImageStatus_IMAGE_STATUS_DELETED {
+			s.images.forget(hash)
+			continue
+		}
+		if img.GetOwnerType() != imagev1.OwnerType_OWNER_TYPE_DRAFT || !strings.HasPrefix(img.GetOwnerId(), draftOwnerPrefix) {
+			continue
+		}
+		if age := time.Since(img.GetCreatedAt().AsTime()); age < minAge {
+			slog.Info("Keeping recent draft image", "kind", kindImage, "id", id, "owner", img.GetOwnerId(), "age", age.Round(time.Second))
+			continue
+		}
+
+		if err := s.deleteImage(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete image %s: %w", id, err)
+		}
+		s.images.forget(hash)
+		deleted++
+		slog.Info("Deleted stale draft image", "kind", kindImage, "id", id, "owner", img.GetOwnerId(), "action", "deleted")
+	}
+
+	if err := s.images.save(); err != nil {
+		return err
+	}
+	slog.Info("Image cleanup complete", "deleted", deleted)
+	return nil
+}
+
+func (s *Seeder) deleteImage(ctx context.Context, id string) error {
+	hard := true
+	_, err := s.imageClient.DeleteImage(ctx, &imagev1.DeleteImageRequest{Id: id, Hard: &hard})
+	return err
+}
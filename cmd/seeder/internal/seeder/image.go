@@ -0,0 +1,238 @@
+package seeder
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+)
+
+func (s *Seeder) uploadImage(ctx context.Context, imageFile, altText string, role imagev1.ImageRole) (string, error) {
+	src, err := fileSource(filepath.Join(s.assetsDir, imageFile))
+	if err != nil {
+		return "", err
+	}
+
+	return s.uploadSource(ctx, imageFile, src, altText, role)
+}
+
+// uploadSource preprocesses src and uploads it to the seed draft through the
+// presign flow, reusing an existing upload of the same source bytes and
+// preprocessing. Products share the upload through copies, which keep its
+// role and alt text: the image service offers no way to change them, so the
+// first product to upload the content sets them for all.
+func (s *Seeder) uploadSource(ctx context.Context, imageFile string, src imageSource, altText string, role imagev1.ImageRole) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "upload image",
+		trace.WithAttributes(attrKind.String(kindImage), attrName.String(imageFile)))
+	defer func() { endSpan(span, err) }()
+
+	start := time.Now()
+	in, err := s.inspectImage(imageFile, src)
+	if err != nil {
+		s.results.recordSince(ctx, start, kindImage, imageFile, outcome{}, err)
+		return "", err
+	}
+
+	key := imageKey(in.sha256, s.imageOpts.variant())
+	return s.dedupeUpload(ctx, key, imageFile, func() (string, error) {
+		img, err := s.prepareImage(ctx, in)
+		if err != nil {
+			return "", err
+		}
+
+		presign, err := s.createPresignURL(ctx, img, role)
+		if err != nil {
+			return "", err
+		}
+
+		if err := s.uploadToStorage(ctx, presign.UploadUrl, img); err != nil {
+			return "", err
+		}
+
+		return s.confirmUpload(ctx, presign.UploadToken, altText, role, img)
+	})
+}
+
+// imageKey identifies an upload for deduplication and in the image cache file.
+// sha256 is taken over the source file; variant describes its preprocessing.
+func imageKey(sha256 []byte, variant string) string {
+	return hex.EncodeToString(sha256) + ":" + variant
+}
+
+func (s *Seeder) createPresignURL(ctx context.Context, img *preparedImage, role imagev1.ImageRole) (*imagev1.CreatePresignResponse, error) {
+	req := &imagev1.CreatePresignRequest{
+		OwnerType:   imagev1.OwnerType_OWNER_TYPE_DRAFT,
+		OwnerId:     s.draftID,
+		Filename:    img.filename,
+		ContentType: img.format.contentType,
+		Size:        img.source.size,
+		Role:        role,
+	}
+
+	return s.imageClient.CreatePresign(ctx, req)
+}
+
+// uploadToStorage streams the image to the presigned URL with a Content-MD5
+// header, plus x-amz-checksum-sha256 when the presign signed it, and checks
+// the returned ETag, retrying transient failures. Large files are streamed in
+// one PUT rather than uploaded in parts: a multipart upload needs a presigned
+// URL per part, which the image service API does not issue.
+func (s *Seeder) uploadToStorage(ctx context.Context, uploadURL string, img *preparedImage) error {
+	parsedURL, err := url.Parse(uploadURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse upload URL: %w", err)
+	}
+
+	ctx, span := tracer.Start(ctx, "PUT object storage", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int64("http.request.body.size", img.source.size)))
+	start := time.Now()
+	err = s.retry.do(ctx, "upload of "+img.filename, retryableStorage, func(ctx context.Context) error {
+		return s.putObject(ctx, parsedURL, img)
+	})
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+
+	uploadBytes.Add(ctx, img.source.size)
+	uploadDuration.Record(ctx, time.Since(start).Seconds())
+	return nil
+}
+
+func (s *Seeder) putObject(ctx context.Context, parsedURL *url.URL, img *preparedImage) error {
+	targetURL, hostHeader := s.resolveUploadURL(*parsedURL)
+
+	body, err := img.source.open()
+	if err != nil {
+		return fmt.Errorf("failed to read image file: %w", err)
+	}
+	defer body.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, targetURL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", img.format.mime)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(img.md5))
+	if presignSignsHeader(parsedURL, "x-amz-checksum-sha256") {
+		req.Header.Set("x-amz-checksum-sha256", base64.StdEncoding.EncodeToString(img.sha256))
+	}
+	req.ContentLength = img.source.size
+	req.Host = hostHeader
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return &storageStatusError{op: "upload", status: resp.StatusCode, body: string(respBody)}
+	}
+
+	return verifyUpload(img, resp.Header.Get("ETag"))
+}
+
+// presignSignsHeader reports whether a SigV4 presigned URL lists header in
+// X-Amz-SignedHeaders; unsigned x-amz-* headers would break the signature.
+// The query is scanned by hand because url.Query drops pairs holding a raw ';'.
+func presignSignsHeader(u *url.URL, header string) bool {
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		value, ok := strings.CutPrefix(pair, "X-Amz-SignedHeaders=")
+		if !ok {
+			continue
+		}
+		signed, err := url.QueryUnescape(value)
+		if err != nil {
+			return false
+		}
+		return slices.Contains(strings.Split(signed, ";"), header)
+	}
+	return false
+}
+
+// verifyUpload compares the ETag of the PUT response, which is the MD5 of a
+// single-part object, with the uploaded content. Storage that honours
+// Content-MD5 already rejects a corrupted body; this catches storage that
+// ignores the header. An empty or multipart ETag is accepted. The stored size
+// is checked by confirmUpload, as a PUT-only presign cannot read it back.
+func verifyUpload(img *preparedImage, etag string) error {
+	etag = strings.Trim(etag, `"`)
+	if etag != "" && !strings.Contains(etag, "-") && etag != hex.EncodeToString(img.md5) {
+		return fmt.Errorf("uploaded object ETag %s does not match MD5 %s", etag, hex.EncodeToString(img.md5))
+	}
+	return nil
+}
+
+// resolveUploadURL returns the actual URL to connect to and the Host header value.
+// When storageHostOverride is set, TCP goes to the override but Host header
+// preserves the original value so the S3 signature remains valid.
+func (s *Seeder) resolveUploadURL(u url.URL) (targetURL, hostHeader string) {
+	hostHeader = u.Host
+	if s.storageHostOverride != "" {
+		u.Host = s.storageHostOverride
+	}
+	return u.String(), hostHeader
+}
+
+// confirmUpload registers the uploaded object with the image service, which
+// reads its size from storage, and deletes the image again when that size
+// differs from the uploaded file, so a truncated upload is not kept.
+func (s *Seeder) confirmUpload(ctx context.Context, uploadToken, altText string, role imagev1.ImageRole, img *preparedImage) (string, error) {
+	checksum := hex.EncodeToString(img.sha256)
+	req := &imagev1.ConfirmUploadRequest{
+		UploadToken: uploadToken,
+		Alt:         altText,
+		Role:        role,
+		Checksum:    &checksum,
+	}
+
+	resp, err := s.imageClient.ConfirmUpload(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to confirm upload: %w", err)
+	}
+
+	if err := verifyStoredSize(resp.GetImage(), img.source.size); err != nil {
+		if delErr := s.deleteImage(ctx, resp.GetImage().GetId()); delErr != nil {
+			slog.Warn("Failed to delete truncated image", "kind", kindImage, "id", resp.GetImage().GetId(), "error", delErr)
+		}
+		return "", fmt.Errorf("image %s: %w", img.filename, err)
+	}
+	return resp.GetImage().GetId(), nil
+}
+
+// verifyStoredSize checks the size the image service recorded for a
+// confirmed upload against the size of the uploaded file.
+func verifyStoredSize(stored *imagev1.Image, size int64) error {
+	if stored.GetSize() != size {
+		return fmt.Errorf("stored object is %d bytes, uploaded %d", stored.GetSize(), size)
+	}
+	return nil
+}
+
+func toImageRole(role string) imagev1.ImageRole {
+	switch strings.ToUpper(role) {
+	case "MAIN":
+		return imagev1.ImageRole_IMAGE_ROLE_MAIN
+	case "GALLERY":
+		return imagev1.ImageRole_IMAGE_ROLE_GALLERY
+	case "OTHER":
+		return imagev1.ImageRole_IMAGE_ROLE_OTHER
+	default:
+		return imagev1.ImageRole_IMAGE_ROLE_UNSPECIFIED
+	}
+}
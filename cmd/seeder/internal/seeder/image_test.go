@@ -0,0 +1,77 @@
+package seeder
+
+import (
+	"crypto/md5"
+	"net/url"
+	"testing"
+
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+)
+
+func TestVerifyUpload(t *testing.T) {
+	sum := md5.Sum([]byte("image"))
+	img := &preparedImage{md5: sum[:]}
+
+	tests := []struct {
+		name    string
+		etag    string
+		wantErr bool
+	}{
+		{"matching", `"78805a221a988e79ef3f42d7c5bfd418"`, false},
+		{"unquoted", "78805a221a988e79ef3f42d7c5bfd418", false},
+		{"missing", "", false},
+		{"multipart", `"d41d8cd98f00b204e9800998ecf8427e-2"`, false},
+		{"corrupted", `"d41d8cd98f00b204e9800998ecf8427e"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyUpload(img, tt.etag); (err != nil) != tt.wantErr {
+				t.Errorf("verifyUpload(%q) error = %v, want error %v", tt.etag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyStoredSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  int64
+		wantErr bool
+	}{
+		{"complete", 1024, false},
+		{"truncated", 512, true},
+		{"empty", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyStoredSize(&imagev1.Image{Size: tt.stored}, 1024)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyStoredSize(%d) error = %v, want error %v", tt.stored, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPresignSignsHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{"signed", "X-Amz-SignedHeaders=content-md5%3Bhost%3Bx-amz-checksum-sha256", true},
+		{"raw separator", "X-Amz-Expires=900&X-Amz-SignedHeaders=host;x-amz-checksum-sha256", true},
+		{"not signed", "X-Amz-SignedHeaders=host", false},
+		{"no signed headers", "X-Amz-Expires=900", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &url.URL{Scheme: "https", Host: "storage", Path: "/bucket/key", RawQuery: tt.query}
+			if got := presignSignsHeader(u, "x-amz-checksum-sha256"); got != tt.want {
+				t.Errorf("presignSignsHeader(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
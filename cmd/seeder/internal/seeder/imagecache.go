@@ -0,0 +1,343 @@
+package seeder
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+)
+
+// imageCache deduplicates uploads by imageKey: the SHA-256 of the source file
+// and its preprocessing. Entries uploaded or verified in this run are reused
+// directly; entries loaded from the persisted cache file are checked against
+// the image service first. Uploads stay on the seed draft and are shared:
+// each product gets its own copy.
+type imageCache struct {
+	mu        sync.Mutex
+	path      string
+	tenant    string
+	verified  map[string]string // key -> imageID, usable in this run
+	persisted map[string]string // key -> imageID, from a previous run
+	removed   map[string]bool   // keys of entries to drop from the file
+	inflight  singleflight.Group
+
+	// Copies attached to each product, in order: as recorded by a previous
+	// run, and as attached in this one.
+	products map[string][]attachedImage
+	attached map[string][]attachedImage
+}
+
+// imageCacheFile is the on-disk format, by tenant.
+type imageCacheFile map[string]*tenantImageCache
+
+// tenantImageCache maps upload keys to image IDs and product IDs to the
+// copies attached to them, in order.
+type tenantImageCache struct {
+	Images   map[string]string          `json:"images"`
+	Products map[string][]attachedImage `json:"products,omitempty"`
+}
+
+// attachedImage is a product's copy of a shared upload.
+type attachedImage struct {
+	Source string `json:"source"`
+	ID     string `json:"id"`
+}
+
+// newImageCache loads the cache file at path; when path is a directory, each
+// tenant gets its own file in it, so Jobs for different tenants sharing a
+// volume never rewrite each other's cache.
+func newImageCache(path, tenant string) (*imageCache, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, cmp.Or(tenant, "default")+".image-cache.json")
+	}
+	c := &imageCache{
+		path:      path,
+		tenant:    tenant,
+		verified:  make(map[string]string),
+		persisted: make(map[string]string),
+		removed:   make(map[string]bool),
+		products:  make(map[string][]attachedImage),
+		attached:  make(map[string][]attachedImage),
+	}
+	if path == "" {
+		return c, nil
+	}
+
+	file, err := readImageCacheFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if entry := file[tenant]; entry != nil {
+		maps.Copy(c.persisted, entry.Images)
+		maps.Copy(c.products, entry.Products)
+	}
+	return c, nil
+}
+
+func readImageCacheFile(path string) (imageCacheFile, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return imageCacheFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image cache: %w", err)
+	}
+
+	file := imageCacheFile{}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse image cache %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// save merges this run's verified entries and attached images into the cache
+// file for the tenant and drops the entries whose images are gone.
+func (c *imageCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := readImageCacheFile(c.path)
+	if err != nil {
+		return err
+	}
+	entry := file[c.tenant]
+	if entry == nil {
+		entry = &tenantImageCache{}
+		file[c.tenant] = entry
+	}
+	if entry.Images == nil {
+		entry.Images = make(map[string]string)
+	}
+	if entry.Products == nil {
+		entry.Products = make(map[string][]attachedImage)
+	}
+	for hash := range c.removed {
+		delete(entry.Images, hash)
+	}
+	for hash, id := range c.verified {
+		entry.Images[hash] = id
+	}
+	maps.Copy(entry.Products, c.attached)
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal image cache: %w", err)
+	}
+	if err := writeFileAtomic(c.path, append(raw, '\n')); err != nil {
+		return fmt.Errorf("failed to write image cache: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at path through a temporary file in the
+// same directory, so a run killed mid-write leaves the previous cache intact.
+func writeFileAtomic(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *imageCache) lookup(hash string) (id string, verified bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id, ok := c.verified[hash]; ok {
+		return id, true
+	}
+	return c.persisted[hash], false
+}
+
+func (c *imageCache) store(hash, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verified[hash] = id
+	delete(c.persisted, hash)
+	delete(c.removed, hash)
+}
+
+func (c *imageCache) forget(hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.persisted, hash)
+	c.removed[hash] = true
+}
+
+// drop forgets every entry pointing at the image ID.
+func (c *imageCache) drop(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for hash, cached := range c.verified {
+		if cached == id {
+			delete(c.verified, hash)
+			c.removed[hash] = true
+		}
+	}
+}
+
+// productImages returns the copies last attached to the product, in order,
+// and whether any attachment was recorded.
+func (c *imageCache) productImages(productID string) ([]attachedImage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ids, ok := c.attached[productID]; ok {
+		return ids, true
+	}
+	ids, ok := c.products[productID]
+	return ids, ok
+}
+
+func (c *imageCache) setProductImages(productID string, images []attachedImage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attached[productID] = slices.Clone(images)
+}
+
+// referenced reports whether a product copy recorded in the cache was made
+// from the upload with the image ID.
+func (c *imageCache) referenced(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	isSource := func(img attachedImage) bool { return img.Source == id }
+	for productID, images := range c.products {
+		if _, ok := c.attached[productID]; !ok && slices.ContainsFunc(images, isSource) {
+			return true
+		}
+	}
+	for _, images := range c.attached {
+		if slices.ContainsFunc(images, isSource) {
+			return true
+		}
+	}
+	return false
+}
+
+// adopt adds entries uploaded by an earlier run that are not cached yet. Like
+// entries from the cache file, they are checked against the image service
+// before reuse.
+func (c *imageCache) adopt(entries map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for hash, id := range entries {
+		if _, ok := c.verified[hash]; !ok {
+			c.persisted[hash] = id
+		}
+	}
+}
+
+// persistedEntries returns a copy of the entries loaded from the cache file.
+func (c *imageCache) persistedEntries() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.persisted)
+}
+
+// dedupeUpload returns the image ID for content with the given hash, calling
+// upload only when neither this run nor a still-existing cached image has it.
+// Concurrent callers with the same hash share one upload.
+func (s *Seeder) dedupeUpload(ctx context.Context, hash, imageFile string, upload func() (string, error)) (string, error) {
+	id, err, _ := s.images.inflight.Do(hash, func() (any, error) {
+		cached, verified := s.images.lookup(hash)
+		if verified {
+			return cached, nil
+		}
+
+		start := time.Now()
+		if cached != "" {
+			ok, err := s.imageExists(ctx, cached)
+			if err != nil {
+				s.results.recordSince(ctx, start, kindImage, imageFile, outcome{id: cached}, err)
+				return "", err
+			}
+			if ok {
+				s.images.store(hash, cached)
+				s.results.recordSince(ctx, start, kindImage, imageFile, outcome{id: cached, action: ActionUnchanged, reason: "reused cached image"}, nil)
+				return cached, nil
+			}
+			s.images.forget(hash)
+		}
+
+		id, err := upload()
+		s.results.recordSince(ctx, start, kindImage, imageFile, outcome{id: id, action: ActionCreated}, err)
+		if err != nil {
+			return "", err
+		}
+		s.uploads.add(id, imageFile)
+		s.images.store(hash, id)
+		if err := s.checkpoint.addImage(hash, id); err != nil {
+			slog.Warn("Failed to update checkpoint", "error", err)
+		}
+		return id, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id.(string), nil
+}
+
+// cachedImage returns the ID of an existing upload of the asset, or "" when it
+// would have to be uploaded.
+func (s *Seeder) cachedImage(ctx context.Context, imageFile string) (string, error) {
+	src, err := fileSource(filepath.Join(s.assetsDir, imageFile))
+	if err != nil {
+		return "", nil
+	}
+	in, err := s.inspectImage(imageFile, src)
+	if err != nil {
+		return "", nil // reported when the upload is attempted
+	}
+
+	key := imageKey(in.sha256, s.imageOpts.variant())
+	id, verified := s.images.lookup(key)
+	if id == "" || verified {
+		return id, nil
+	}
+	ok, err := s.imageExists(ctx, id)
+	if err != nil || !ok {
+		return "", err
+	}
+	s.images.store(key, id)
+	return id, nil
+}
+
+func (s *Seeder) imageExists(ctx context.Context, id string) (bool, error) {
+	resp, err := s.imageClient.GetImage(ctx, &imagev1.GetImageRequest{Id: id})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up cached image %s: %w", id, err)
+	}
+	return resp.GetImage().GetStatus() != imagev1.ImageStatus_IMAGE_STATUS_DELETED, nil
+}
@@ -0,0 +1,76 @@
+package seeder
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestImageCacheDirectoryPerTenant(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tenant := range []string{"acme", "globex"} {
+		c, err := newImageCache(dir, tenant)
+		if err != nil {
+			t.Fatalf("newImageCache(%s) error = %v", tenant, err)
+		}
+		c.store("hash", tenant+"-img")
+		c.setProductImages("p1", []attachedImage{{Source: tenant + "-img", ID: tenant + "-copy"}})
+		if err := c.save(); err != nil {
+			t.Fatalf("save(%s) error = %v", tenant, err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"acme.image-cache.json", "globex.image-cache.json"}; !slices.Equal(names, want) {
+		t.Errorf("cache directory holds %v, want %v", names, want)
+	}
+
+	c, err := newImageCache(dir, "acme")
+	if err != nil {
+		t.Fatalf("newImageCache() error = %v", err)
+	}
+	if id, verified := c.lookup("hash"); id != "acme-img" || verified {
+		t.Errorf("lookup() = %q, %v, want the persisted acme image", id, verified)
+	}
+	if !c.referenced("acme-img") || c.referenced("globex-img") {
+		t.Error("referenced() should only see the tenant's own product copies")
+	}
+}
+
+func TestImageCacheSaveKeepsOtherTenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image-cache.json")
+
+	for _, tenant := range []string{"acme", "globex"} {
+		c, err := newImageCache(path, tenant)
+		if err != nil {
+			t.Fatalf("newImageCache(%s) error = %v", tenant, err)
+		}
+		c.store("hash", tenant+"-img")
+		if err := c.save(); err != nil {
+			t.Fatalf("save(%s) error = %v", tenant, err)
+		}
+	}
+
+	file, err := readImageCacheFile(path)
+	if err != nil {
+		t.Fatalf("readImageCacheFile() error = %v", err)
+	}
+	for _, tenant := range []string{"acme", "globex"} {
+		if got := file[tenant].Images["hash"]; got != tenant+"-img" {
+			t.Errorf("file[%s] = %q, want %q", tenant, got, tenant+"-img")
+		}
+	}
+	matches, _ := filepath.Glob(path + ".*.tmp")
+	if len(matches) > 0 {
+		t.Errorf("save() left temporary files %v", matches)
+	}
+}
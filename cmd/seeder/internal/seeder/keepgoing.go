@@ -0,0 +1,47 @@
+package seeder
+
+import (
+	"errors"
+
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+// EntityFailures is returned by Run in --keep-going mode when some entities
+// failed while the rest of the run completed.
+type EntityFailures struct {
+	Errs []error
+}
+
+func (e *EntityFailures) Error() string {
+	return "some entities failed:\n" + errors.Join(e.Errs...).Error()
+}
+
+func (e *EntityFailures) Unwrap() []error {
+	return e.Errs
+}
+
+// failedCategoryDependency returns the failed attribute a category is bound
+// to, or "" when all of them were written.
+func (s *Seeder) failedCategoryDependency(cat data.Category) string {
+	for _, ca := range cat.Attributes {
+		if s.results.isBlocked(kindAttribute, ca.AttributeID) {
+			return kindAttribute + " " + ca.AttributeID
+		}
+	}
+	return ""
+}
+
+// failedProductDependency returns the failed category or attribute a product
+// references, or "" when all of them were written. A category skipped because
+// of a failed attribute counts as failed.
+func (s *Seeder) failedProductDependency(prod data.Product) string {
+	if prod.CategoryID != "" && s.results.isBlocked(kindCategory, prod.CategoryID) {
+		return kindCategory + " " + prod.CategoryID
+	}
+	for _, pa := range prod.Attributes {
+		if s.results.isBlocked(kindAttribute, pa.AttributeID) {
+			return kindAttribute + " " + pa.AttributeID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package seeder
+
+import (
+	"testing"
+
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+func TestFailedProductDependency(t *testing.T) {
+	results := newRunReport()
+	results.record(EntityResult{Kind: kindAttribute, ID: "a-failed", Action: ActionFailed})
+	results.record(EntityResult{Kind: kindAttribute, ID: "a-ok", Action: ActionCreated})
+	results.block(kindCategory, "c-skipped")
+	s := &Seeder{results: results}
+
+	tests := []struct {
+		name string
+		prod data.Product
+		want string
+	}{
+		{"no failed dependencies", data.Product{CategoryID: "c-ok", Attributes: []data.ProductAttribute{{AttributeID: "a-ok"}}}, ""},
+		{"skipped category", data.Product{CategoryID: "c-skipped"}, "category c-skipped"},
+		{"failed attribute", data.Product{CategoryID: "c-ok", Attributes: []data.ProductAttribute{{AttributeID: "a-failed"}}}, "attribute a-failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.failedProductDependency(tt.prod); got != tt.want {
+				t.Errorf("failedProductDependency() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	cat := data.Category{Attributes: []data.CategoryAttribute{{AttributeID: "a-ok"}, {AttributeID: "a-failed"}}}
+	if got := s.failedCategoryDependency(cat); got != "attribute a-failed" {
+		t.Errorf("failedCategoryDependency() = %q, want %q", got, "attribute a-failed")
+	}
+}
@@ -0,0 +1,212 @@
+package seeder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+// Placeholders are drawn on a small canvas with the built-in bitmap font and
+// scaled up, which keeps the text legible without shipping a font file.
+const (
+	placeholderCanvas = 200
+	placeholderScale  = 4
+	placeholderMargin = 12
+)
+
+// uploadPlaceholder renders and uploads a placeholder main image for a product
+// that has no asset. The same product data always yields the same bytes, so
+// reruns hit the image cache instead of uploading again.
+func (s *Seeder) uploadPlaceholder(ctx context.Context, prod data.Product) string {
+	content, err := s.renderPlaceholder(prod)
+	if err != nil {
+		slog.Warn("Failed to render placeholder", "kind", kindProduct, "id", prod.ID, "name", prod.Name, "error", err)
+		return ""
+	}
+
+	filename := "placeholder-" + prod.ID + ".png"
+	imgID, err := s.uploadSource(ctx, filename, bytesSource(content), prod.Name, imagev1.ImageRole_IMAGE_ROLE_MAIN)
+	if err != nil {
+		slog.Warn("Failed to upload placeholder", "kind", kindProduct, "id", prod.ID, "name", prod.Name, "error", err)
+		return ""
+	}
+	slog.Info("Using placeholder image", "kind", kindProduct, "id", prod.ID, "name", prod.Name)
+	return imgID
+}
+
+// renderPlaceholder draws the product name and its key attribute on a
+// background colored by category.
+func (s *Seeder) renderPlaceholder(prod data.Product) ([]byte, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, placeholderCanvas, placeholderCanvas))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(categoryColor(prod.CategoryID)), image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	lineHeight := face.Metrics().Height.Ceil() + 2
+	maxChars := (placeholderCanvas - 2*placeholderMargin) / face.Advance
+
+	lines := wrapText(prod.Name, maxChars)
+	if label := s.keyAttributeLabel(prod); label != "" {
+		lines = append(lines, "")
+		lines = append(lines, wrapText(label, maxChars)...)
+	}
+
+	d := &font.Drawer{Dst: canvas, Src: image.White, Face: face}
+	y := (placeholderCanvas-len(lines)*lineHeight)/2 + face.Ascent
+	for _, line := range lines {
+		width := d.MeasureString(line).Ceil()
+		d.Dot = fixed.P((placeholderCanvas-width)/2, y)
+		d.DrawString(line)
+		y += lineHeight
+	}
+
+	size := placeholderCanvas * placeholderScale
+	out := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.NearestNeighbor.Scale(out, out.Bounds(), canvas, canvas.Bounds(), draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, out); err != nil {
+		return nil, fmt.Errorf("failed to encode placeholder: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// keyAttributeLabel formats the product's first variant attribute (falling
+// back to its first attribute) as "Name: value".
+func (s *Seeder) keyAttributeLabel(prod data.Product) string {
+	if len(prod.Attributes) == 0 {
+		return ""
+	}
+
+	key := prod.Attributes[0]
+	variants := make(map[string]bool)
+	for _, cat := range s.data.Categories {
+		if cat.ID != prod.CategoryID {
+			continue
+		}
+		for _, ca := range cat.Attributes {
+			if ca.Role == "variant" {
+				variants[ca.AttributeID] = true
+			}
+		}
+	}
+	for _, pa := range prod.Attributes {
+		if variants[pa.AttributeID] {
+			key = pa
+			break
+		}
+	}
+
+	for _, attr := range s.data.Attributes {
+		if attr.ID == key.AttributeID {
+			return attr.Name + ": " + formatPlaceholderValue(attr, key)
+		}
+	}
+	return ""
+}
+
+func formatPlaceholderValue(attr data.Attribute, pa data.ProductAttribute) string {
+	optionName := func(slug string) string {
+		for _, opt := range attr.Options {
+			if opt.Slug == slug {
+				return opt.Name
+			}
+		}
+		return slug
+	}
+
+	switch {
+	case pa.OptionSlugValue != "":
+		return optionName(pa.OptionSlugValue)
+	case len(pa.OptionSlugValues) > 0:
+		names := make([]string, len(pa.OptionSlugValues))
+		for i, slug := range pa.OptionSlugValues {
+			names[i] = optionName(slug)
+		}
+		return strings.Join(names, ", ")
+	case pa.NumericValue != nil:
+		return strings.TrimSpace(strconv.FormatFloat(*pa.NumericValue, 'g', -1, 64) + " " + attr.Unit)
+	case pa.BooleanValue != nil:
+		if *pa.BooleanValue {
+			return "Yes"
+		}
+		return "No"
+	default:
+		return pa.TextValue
+	}
+}
+
+// categoryColor derives a stable, reasonably dark background color from the category ID.
+func categoryColor(categoryID string) color.RGBA {
+	h := fnv.New32a()
+	h.Write([]byte(categoryID))
+	hue := float64(h.Sum32()%360) / 60
+
+	const v, sat = 0.55, 0.6
+	c := v * sat
+	x := c * (1 - math.Abs(math.Mod(hue, 2)-1))
+	var r, g, b float64
+	switch int(hue) {
+	case 0:
+		r, g = c, x
+	case 1:
+		r, g = x, c
+	case 2:
+		g, b = c, x
+	case 3:
+		g, b = x, c
+	case 4:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := v - c
+	return color.RGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 255}
+}
+
+// wrapText splits text into lines of at most width characters on word
+// boundaries. Widths count runes, so non-ASCII names are never cut mid-character.
+func wrapText(text string, width int) []string {
+	var lines []string
+	var line string
+	for _, field := range strings.Fields(text) {
+		word := []rune(field)
+		for len(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, string(word[:width]))
+			word = word[width:]
+		}
+		switch {
+		case line == "":
+			line = string(word)
+		case utf8.RuneCountInString(line)+1+len(word) <= width:
+			line += " " + string(word)
+		default:
+			lines = append(lines, line)
+			line = string(word)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,37 @@
+package seeder
+
+import (
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"fits", "Smart phone", 12, []string{"Smart phone"}},
+		{"wraps on words", "Smart phone case", 11, []string{"Smart phone", "case"}},
+		{"splits long words", "Ultrawideband", 5, []string{"Ultra", "wideb", "and"}},
+		{"counts runes", "Смартфон чохол", 8, []string{"Смартфон", "чохол"}},
+		{"splits long words on runes", "Навушники", 4, []string{"Наву", "шник", "и"}},
+		{"empty", "  ", 5, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapText(tt.text, tt.width)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+			for _, line := range got {
+				if !utf8.ValidString(line) {
+					t.Errorf("wrapText(%q, %d) produced invalid UTF-8 line %q", tt.text, tt.width, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type planAction string
+
+const (
+	planCreate    planAction = "create"
+	planUpdate    planAction = "update"
+	planUnchanged planAction = "unchanged"
+)
+
+type planSummary map[planAction]int
+
+// Plan fetches the current state of every attribute, category and product and
+// prints what Run would do, with field-level diffs for updates. It performs
+// no writes and uploads no images.
+func (s *Seeder) Plan(ctx context.Context) error {
+	summary := planSummary{}
+
+	slog.Info("Planning attributes")
+	for _, attr := range s.data.Attributes {
+		existing, err := s.getAttribute(ctx, attr.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check attribute %s: %w", attr.Name, err)
+		}
+		if existing == nil {
+			summary.print(planCreate, "attribute", attr.Name, attr.ID, nil)
+			continue
+		}
+		warnImmutableAttributeFields(attr, existing)
+		summary.printDiff("attribute", attr.Name, attr.ID, attributeDiff(attr, existing))
+	}
+
+	slog.Info("Planning categories")
+	for _, cat := range s.data.Categories {
+		existing, err := s.getCategory(ctx, cat.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check category %s: %w", cat.Name, err)
+		}
+		if existing == nil {
+			summary.print(planCreate, "category", cat.Name, cat.ID, nil)
+			continue
+		}
+		summary.printDiff("category", cat.Name, cat.ID, categoryDiff(cat, existing))
+	}
+
+	slog.Info("Planning products")
+	for _, prod := range s.data.Products {
+		existing, err := s.getProduct(ctx, prod.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check product %s: %w", prod.Name, err)
+		}
+		if existing == nil {
+			summary.print(planCreate, "product", prod.Name, prod.ID, nil)
+			continue
+		}
+		diffs, err := s.productChanges(ctx, prod, existing)
+		if err != nil {
+			return fmt.Errorf("failed to check images of product %s: %w", prod.Name, err)
+		}
+		summary.printDiff("product", prod.Name, prod.ID, diffs)
+	}
+
+	slog.Info("Plan complete",
+		"create", summary[planCreate], "update", summary[planUpdate], "unchanged", summary[planUnchanged])
+	return nil
+}
+
+func (p planSummary) printDiff(kind, name, id string, diffs []fieldDiff) {
+	if len(diffs) == 0 {
+		p.print(planUnchanged, kind, name, id, nil)
+		return
+	}
+	p.print(planUpdate, kind, name, id, diffs)
+}
+
+func (p planSummary) print(action planAction, kind, name, id string, diffs []fieldDiff) {
+	p[action]++
+	attrs := []any{"kind", kind, "id", id, "name", name, "action", action}
+	if len(diffs) > 0 {
+		attrs = append(attrs, "changes", diffStrings(diffs))
+	}
+	slog.Info("Planned", attrs...)
+}
@@ -0,0 +1,66 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+)
+
+// forEach runs fn for every item on at most concurrency workers. The first
+// error cancels the context passed to the remaining calls and is returned.
+// With keepGoing, every item runs and all errors are returned joined.
+func forEach[T any](parent context.Context, concurrency int, keepGoing bool, items []T, fn func(context.Context, T) error) error {
+	g, ctx := errgroup.WithContext(parent)
+	g.SetLimit(max(concurrency, 1))
+
+	var mu sync.Mutex
+	var errs []error
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			err := fn(ctx, item)
+			if err != nil && keepGoing {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return nil
+			}
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if err := parent.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// newRateLimiter returns a client-side limiter for qps requests per second, or nil when qps <= 0.
+func newRateLimiter(qps float64) *rate.Limiter {
+	if qps <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(qps), 1)
+}
+
+// rateLimitInterceptor delays every unary RPC until the shared limiter allows it.
+func rateLimitInterceptor(limiter *rate.Limiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
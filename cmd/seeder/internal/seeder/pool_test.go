@@ -0,0 +1,70 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	errB := errors.New("b failed")
+	errD := errors.New("d failed")
+	fail := map[string]error{"b": errB, "d": errD}
+	items := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name      string
+		keepGoing bool
+		wantAll   bool
+		wantErrs  []error
+	}{
+		{name: "stops at the first error", wantErrs: []error{errB}},
+		{name: "keep going runs every item", keepGoing: true, wantAll: true, wantErrs: []error{errB, errD}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var ran []string
+			err := forEach(context.Background(), 1, tt.keepGoing, items, func(ctx context.Context, item string) error {
+				mu.Lock()
+				ran = append(ran, item)
+				mu.Unlock()
+				return fail[item]
+			})
+
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("forEach() error = %v, want it to include %v", err, want)
+				}
+			}
+			if tt.wantAll && !slices.Equal(ran, items) {
+				t.Errorf("forEach() ran %v, want %v", ran, items)
+			}
+			if !tt.wantAll && slices.Contains(ran, "e") {
+				t.Errorf("forEach() ran %v after the first error", ran)
+			}
+		})
+	}
+}
+
+func TestForEachKeepGoingStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran []int
+	err := forEach(ctx, 1, true, []int{1, 2, 3}, func(ctx context.Context, item int) error {
+		ran = append(ran, item)
+		if item == 1 {
+			cancel()
+		}
+		return ctx.Err()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("forEach() error = %v, want context.Canceled", err)
+	}
+	if slices.Contains(ran, 3) {
+		t.Errorf("forEach() ran %v after cancellation", ran)
+	}
+}
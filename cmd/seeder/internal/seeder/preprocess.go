@@ -0,0 +1,377 @@
+package seeder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the WebP decoder with image.Decode
+
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+)
+
+// maxImagePixels guards against decompression bombs: images are rejected
+// before decoding when their header declares more pixels than this.
+const maxImagePixels = 50_000_000
+
+// imageFormat is an upload format accepted by the image service.
+type imageFormat struct {
+	name        string
+	ext         string
+	mime        string
+	contentType imagev1.ImageContentType
+}
+
+var (
+	formatJPEG = imageFormat{"jpeg", ".jpg", "image/jpeg", imagev1.ImageContentType_IMAGE_CONTENT_TYPE_JPEG}
+	formatPNG  = imageFormat{"png", ".png", "image/png", imagev1.ImageContentType_IMAGE_CONTENT_TYPE_PNG}
+	formatWebP = imageFormat{"webp", ".webp", "image/webp", imagev1.ImageContentType_IMAGE_CONTENT_TYPE_WEBP}
+	formatAVIF = imageFormat{"avif", ".avif", "image/avif", imagev1.ImageContentType_IMAGE_CONTENT_TYPE_AVIF}
+)
+
+var imageFormats = map[string]imageFormat{
+	formatJPEG.name: formatJPEG,
+	formatPNG.name:  formatPNG,
+	formatWebP.name: formatWebP,
+	formatAVIF.name: formatAVIF,
+}
+
+// externalEncoders are the CLI tools used for formats Go cannot encode
+// natively. The published seeder image does not ship them.
+var externalEncoders = map[string]string{
+	formatWebP.name: "cwebp",
+	formatAVIF.name: "avifenc",
+}
+
+// imageOptions controls preprocessing of asset files before upload.
+type imageOptions struct {
+	maxBytes     int
+	maxDimension int
+	format       string // target format; empty keeps the source format
+}
+
+func (o imageOptions) validate() error {
+	if o.maxBytes < 0 || o.maxDimension < 0 {
+		return fmt.Errorf("image size limits must not be negative")
+	}
+	if o.format == "" {
+		return nil
+	}
+	if _, ok := imageFormats[o.format]; !ok {
+		return fmt.Errorf("invalid image format %q: expected jpeg, png, webp or avif", o.format)
+	}
+	if tool, ok := externalEncoders[o.format]; ok {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("image format %s requires %s on PATH: %w", o.format, tool, err)
+		}
+	}
+	return nil
+}
+
+// variant describes the preprocessing that changes image content, so cached
+// uploads made with other settings are not reused.
+func (o imageOptions) variant() string {
+	return fmt.Sprintf("%d/%s", o.maxDimension, o.format)
+}
+
+// imageSource is image content that can be read more than once. Files are
+// streamed from disk on every pass instead of being held in memory.
+type imageSource struct {
+	size int64
+	open func() (io.ReadCloser, error)
+}
+
+func fileSource(path string) (imageSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return imageSource{}, fmt.Errorf("image file not found: %s", path)
+	}
+	return imageSource{
+		size: info.Size(),
+		open: func() (io.ReadCloser, error) { return os.Open(path) },
+	}, nil
+}
+
+func bytesSource(content []byte) imageSource {
+	return imageSource{
+		size: int64(len(content)),
+		open: func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(content)), nil },
+	}
+}
+
+// read calls fn with a fresh reader over the whole content.
+func (src imageSource) read(fn func(io.Reader) error) error {
+	r, err := src.open()
+	if err != nil {
+		return fmt.Errorf("failed to read image file: %w", err)
+	}
+	defer r.Close()
+	return fn(bufio.NewReader(r))
+}
+
+// sourceImage is an asset identified without decoding it: its sniffed format
+// and the SHA-256 of its content, which keys deduplication.
+type sourceImage struct {
+	filename string
+	source   imageSource
+	format   imageFormat
+	sha256   []byte
+}
+
+// inspectImage checks the size limit, sniffs the real format of src and hashes
+// it, so a cached upload is found before paying for a decode.
+func (s *Seeder) inspectImage(imageFile string, src imageSource) (*sourceImage, error) {
+	if limit := s.imageOpts.maxBytes; limit > 0 && src.size > int64(limit) {
+		return nil, fmt.Errorf("image %s is %d bytes, exceeding the limit of %d", imageFile, src.size, limit)
+	}
+
+	var header []byte
+	sha := sha256.New()
+	err := src.read(func(r io.Reader) (err error) {
+		header, err = io.ReadAll(io.LimitReader(io.TeeReader(r, sha), 32))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(sha, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := sniffFormat(header)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", imageFile, err)
+	}
+	return &sourceImage{filename: imageFile, source: src, format: format, sha256: sha.Sum(nil)}, nil
+}
+
+// preparedImage is an asset ready for upload, with the digests used for
+// integrity headers.
+type preparedImage struct {
+	filename string
+	source   imageSource
+	format   imageFormat
+	sha256   []byte
+	md5      []byte
+}
+
+// prepareImage rejects corrupt or oversized images and applies the configured
+// downscaling and transcoding. Content that needs no changes is passed through
+// untouched.
+func (s *Seeder) prepareImage(ctx context.Context, in *sourceImage) (*preparedImage, error) {
+	opts := s.imageOpts
+	imageFile, src, source := in.filename, in.source, in.format
+	target := source
+	if opts.format != "" {
+		target = imageFormats[opts.format]
+	}
+
+	// Go has no AVIF decoder, so AVIF sources are uploaded as they are.
+	if source == formatAVIF {
+		if target != formatAVIF {
+			return nil, fmt.Errorf("image %s: cannot transcode AVIF to %s", imageFile, target.name)
+		}
+		return newPreparedImage(withExt(imageFile, source.ext), src, source)
+	}
+
+	var cfg image.Config
+	err := src.read(func(r io.Reader) (err error) {
+		cfg, _, err = image.DecodeConfig(r)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("image %s is corrupt: %w", imageFile, err)
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("image %s is %dx%d, exceeding the limit of %d pixels", imageFile, cfg.Width, cfg.Height, maxImagePixels)
+	}
+
+	var img image.Image
+	err = src.read(func(r io.Reader) (err error) {
+		img, _, err = image.Decode(r)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("image %s is corrupt: %w", imageFile, err)
+	}
+
+	resized := false
+	if opts.maxDimension > 0 && max(cfg.Width, cfg.Height) > opts.maxDimension {
+		img = downscale(img, opts.maxDimension)
+		resized = true
+	}
+
+	if !resized && target == source {
+		return newPreparedImage(withExt(imageFile, source.ext), src, source)
+	}
+
+	// A resized WebP keeps its format only when cwebp is available.
+	if !canEncode(target) {
+		target = fallbackFormat(img)
+	}
+	out, err := encodeImage(ctx, img, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image %s as %s: %w", imageFile, target.name, err)
+	}
+	return newPreparedImage(withExt(imageFile, target.ext), bytesSource(out), target)
+}
+
+// newPreparedImage computes the SHA-256 and MD5 digests of src in one streaming pass.
+func newPreparedImage(filename string, src imageSource, format imageFormat) (*preparedImage, error) {
+	sha := sha256.New()
+	sum := md5.New()
+	err := src.read(func(r io.Reader) error {
+		_, err := io.Copy(io.MultiWriter(sha, sum), r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &preparedImage{
+		filename: filename,
+		source:   src,
+		format:   format,
+		sha256:   sha.Sum(nil),
+		md5:      sum.Sum(nil),
+	}, nil
+}
+
+// sniffFormat identifies the image format from its magic bytes.
+func sniffFormat(content []byte) (imageFormat, error) {
+	switch {
+	case bytes.HasPrefix(content, []byte("\xff\xd8\xff")):
+		return formatJPEG, nil
+	case bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")):
+		return formatPNG, nil
+	case len(content) >= 12 && string(content[0:4]) == "RIFF" && string(content[8:12]) == "WEBP":
+		return formatWebP, nil
+	case isAVIF(content):
+		return formatAVIF, nil
+	default:
+		return imageFormat{}, fmt.Errorf("unrecognized image format")
+	}
+}
+
+// isAVIF checks the ISO BMFF ftyp box for an avif/avis major or compatible brand.
+func isAVIF(content []byte) bool {
+	if len(content) < 16 || string(content[4:8]) != "ftyp" {
+		return false
+	}
+	boxSize := int(content[0])<<24 | int(content[1])<<16 | int(content[2])<<8 | int(content[3])
+	boxSize = min(boxSize, len(content))
+	for i := 8; i+4 <= boxSize; i += 4 {
+		if i == 12 {
+			continue // minor version
+		}
+		if brand := string(content[i : i+4]); brand == "avif" || brand == "avis" {
+			return true
+		}
+	}
+	return false
+}
+
+// downscale resizes img so that its longer side equals maxDimension.
+func downscale(img image.Image, maxDimension int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w >= h {
+		h = max(h*maxDimension/w, 1)
+		w = maxDimension
+	} else {
+		w = max(w*maxDimension/h, 1)
+		h = maxDimension
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// canEncode reports whether images can be encoded as format here.
+func canEncode(format imageFormat) bool {
+	tool, ok := externalEncoders[format.name]
+	if !ok {
+		return true
+	}
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
+
+// fallbackFormat is the format used when the source format cannot be encoded:
+// JPEG for opaque images, PNG to keep transparency.
+func fallbackFormat(img image.Image) imageFormat {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return formatJPEG
+	}
+	return formatPNG
+}
+
+func encodeImage(ctx context.Context, img image.Image, format imageFormat) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case formatJPEG:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+	case formatPNG:
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	default:
+		return encodeExternal(ctx, img, format)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeExternal hands a lossless PNG of img to the format's CLI encoder.
+func encodeExternal(ctx context.Context, img image.Image, format imageFormat) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "seeder-image-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in.png")
+	out := filepath.Join(dir, "out"+format.ext)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(in, buf.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+
+	var cmd *exec.Cmd
+	switch format {
+	case formatWebP:
+		cmd = exec.CommandContext(ctx, "cwebp", "-quiet", "-q", "85", in, "-o", out)
+	case formatAVIF:
+		cmd = exec.CommandContext(ctx, "avifenc", "-q", "60", in, out)
+	default:
+		return nil, fmt.Errorf("no encoder for %s", format.name)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(output)))
+	}
+
+	return os.ReadFile(out)
+}
+
+// withExt replaces the extension of filename so it matches the uploaded format.
+func withExt(filename, ext string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ext
+}
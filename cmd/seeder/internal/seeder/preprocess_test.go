@@ -0,0 +1,143 @@
+package seeder
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrepareImage(t *testing.T) {
+	const webpFile = "blue-purple-pink.lossy.webp"
+	pngSrc := bytesSource(encodeTestPNG(t, 200, 100))
+
+	tests := []struct {
+		name         string
+		file         string
+		src          imageSource
+		opts         imageOptions
+		wantFormat   imageFormat
+		wantFilename string
+		wantMaxSide  int  // 0 skips the dimension check
+		wantSame     bool // uploaded untouched
+	}{
+		{
+			name: "passthrough", file: "a.png", src: pngSrc,
+			wantFormat: formatPNG, wantFilename: "a.png", wantSame: true,
+		},
+		{
+			name: "resize png", file: "a.png", src: pngSrc, opts: imageOptions{maxDimension: 50},
+			wantFormat: formatPNG, wantFilename: "a.png", wantMaxSide: 50,
+		},
+		{
+			name: "transcode to jpeg", file: "a.png", src: pngSrc, opts: imageOptions{format: "jpeg"},
+			wantFormat: formatJPEG, wantFilename: "a.jpg", wantMaxSide: 200,
+		},
+		{
+			name: "webp passthrough", file: webpFile, src: testFileSource(t, webpFile),
+			wantFormat: formatWebP, wantFilename: webpFile, wantSame: true,
+		},
+		{
+			// Without cwebp on PATH a resized WebP falls back to JPEG, as it is opaque.
+			name: "resize webp without encoder", file: webpFile, src: testFileSource(t, webpFile), opts: imageOptions{maxDimension: 32},
+			wantFormat: formatJPEG, wantFilename: "blue-purple-pink.lossy.jpg", wantMaxSide: 32,
+		},
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Seeder{imageOpts: tt.opts}
+			in, err := s.inspectImage(tt.file, tt.src)
+			if err != nil {
+				t.Fatalf("inspectImage() error = %v", err)
+			}
+			img, err := s.prepareImage(context.Background(), in)
+			if err != nil {
+				t.Fatalf("prepareImage() error = %v", err)
+			}
+
+			if img.format != tt.wantFormat {
+				t.Errorf("format = %s, want %s", img.format.name, tt.wantFormat.name)
+			}
+			if img.filename != tt.wantFilename {
+				t.Errorf("filename = %s, want %s", img.filename, tt.wantFilename)
+			}
+			if same := bytes.Equal(img.sha256, in.sha256); same != tt.wantSame {
+				t.Errorf("content unchanged = %v, want %v", same, tt.wantSame)
+			}
+
+			out := readSource(t, img.source)
+			if sniffed, err := sniffFormat(out); err != nil || sniffed != tt.wantFormat {
+				t.Errorf("uploaded content sniffs as %s (%v), want %s", sniffed.name, err, tt.wantFormat.name)
+			}
+			if tt.wantMaxSide > 0 {
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(out))
+				if err != nil {
+					t.Fatalf("decode prepared image: %v", err)
+				}
+				if side := max(cfg.Width, cfg.Height); side != tt.wantMaxSide {
+					t.Errorf("longer side = %d, want %d", side, tt.wantMaxSide)
+				}
+			}
+		})
+	}
+}
+
+func TestInspectImageRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		src  imageSource
+		opts imageOptions
+	}{
+		{"over size limit", bytesSource(encodeTestPNG(t, 10, 10)), imageOptions{maxBytes: 10}},
+		{"unknown format", bytesSource([]byte("GIF89a not supported")), imageOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Seeder{imageOpts: tt.opts}
+			if _, err := s.inspectImage("a", tt.src); err == nil {
+				t.Error("inspectImage() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func encodeTestPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	out, err := encodeImage(context.Background(), img, formatPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func testFileSource(t *testing.T, name string) imageSource {
+	t.Helper()
+	src, err := fileSource(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func readSource(t *testing.T, src imageSource) []byte {
+	t.Helper()
+	r, err := src.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
@@ -0,0 +1,501 @@
+package seeder
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+)
+
+func (s *Seeder) upsertProducts(ctx context.Context) error {
+	key := func(prod data.Product) (string, string) { return prod.Name, prod.ID }
+	return forEach(ctx, s.concurrency, s.keepGoing, s.data.Products, tracked(s.results, kindProduct, key, checkpointed(s.checkpoint, kindProduct, key, s.upsertProduct)))
+}
+
+func (s *Seeder) upsertProduct(ctx context.Context, prod data.Product) (outcome, error) {
+	if dep := s.failedProductDependency(prod); dep != "" {
+		return outcome{id: prod.ID, action: ActionSkipped, reason: "depends on failed " + dep}, nil
+	}
+
+	existing, err := s.getProduct(ctx, prod.ID)
+	if err != nil {
+		return outcome{}, fmt.Errorf("failed to check product %s: %w", prod.Name, err)
+	}
+
+	if existing != nil {
+		unchanged, err := s.productUnchanged(ctx, prod, existing)
+		if err != nil {
+			return outcome{}, fmt.Errorf("failed to check images of product %s: %w", prod.Name, err)
+		}
+		if unchanged {
+			return outcome{id: prod.ID, action: ActionUnchanged}, nil
+		}
+		images := s.productImageCopies(ctx, prod)
+		out, err := s.updateWithConflictPolicy(ctx, "product", prod.Name, prod.ID, existing.Version,
+			func(ctx context.Context) (int64, bool, error) { return s.currentProduct(ctx, prod, images.mainID()) },
+			func(ctx context.Context, version int64) (string, error) {
+				return s.updateProduct(ctx, prod, images, version)
+			})
+		if err == nil && out.action == ActionUpdated {
+			s.finishProductImages(ctx, prod.ID, images)
+		}
+		return out, err
+	}
+	return s.createProduct(ctx, prod)
+}
+
+// productUnchanged reports whether an update would be a no-op.
+func (s *Seeder) productUnchanged(ctx context.Context, prod data.Product, existing *catalogv1.Product) (bool, error) {
+	diffs, err := s.productChanges(ctx, prod, existing)
+	return len(diffs) == 0, err
+}
+
+// productChanges returns what an update would change. Without an images list,
+// the image is treated as up to date when the product already has one and an
+// asset exists, so unchanged products are not re-uploaded on every run.
+func (s *Seeder) productChanges(ctx context.Context, prod data.Product, existing *catalogv1.Product) ([]fieldDiff, error) {
+	hasImage := s.hasProductImage(prod)
+	diffs := productDiff(prod, prod.Enabled && hasImage, existing)
+	if current := existing.GetImageId(); (current != "") != hasImage {
+		to := "<new>"
+		if !hasImage {
+			to = absent
+		}
+		diffs = append(diffs, fieldDiff{Field: "image", From: cmp.Or(current, absent), To: to})
+	}
+	imageDiffs, err := s.productImagesDiff(ctx, prod, existing)
+	if err != nil {
+		return nil, err
+	}
+	return append(diffs, imageDiffs...), nil
+}
+
+// productImagesDiff compares an explicit images list, with the file and order
+// of each image and which one is main, against the copies an earlier run
+// attached to the product. Only the image cache records those, so without
+// --image-cache the images always count as changed and are attached again.
+func (s *Seeder) productImagesDiff(ctx context.Context, prod data.Product, existing *catalogv1.Product) ([]fieldDiff, error) {
+	if len(prod.Images) == 0 {
+		return nil, nil
+	}
+
+	attached, known := s.images.productImages(prod.ID)
+	current, err := s.productImagesAttached(ctx, prod, existing.GetImageId(), attached)
+	if err != nil {
+		return nil, err
+	}
+	if known && current {
+		return nil, nil
+	}
+
+	from := "unknown"
+	if known {
+		from = fmt.Sprintf("%d attached", len(attached))
+	}
+	files := make([]string, 0, len(prod.Images))
+	for _, img := range productImages(prod) {
+		files = append(files, img.File+" ("+img.Role+")")
+	}
+	return []fieldDiff{{Field: "images", From: from, To: "[" + strings.Join(files, ", ") + "]"}}, nil
+}
+
+// productImagesAttached reports whether attached holds copies of exactly the
+// uploads the images list resolves to, in the order productImageCopies
+// attaches them, with the main copy set on the product. It is false when one
+// of the images still has to be uploaded.
+func (s *Seeder) productImagesAttached(ctx context.Context, prod data.Product, currentMainID string, attached []attachedImage) (bool, error) {
+	var mainID string
+	var extraIDs []string
+	for _, img := range productImages(prod) {
+		if !s.imageFileExists(img.File) {
+			continue
+		}
+		id, err := s.cachedImage(ctx, img.File)
+		if err != nil || id == "" {
+			return false, err
+		}
+		if img.Role == "main" && mainID == "" {
+			mainID = id
+		} else {
+			extraIDs = append(extraIDs, id)
+		}
+	}
+	if mainID == "" && s.placeholders && len(attached) > 0 {
+		// A placeholder is rendered from fields productDiff already compares.
+		mainID = attached[0].Source
+	}
+	if mainID != "" && (len(attached) == 0 || attached[0].ID != currentMainID) {
+		return false, nil
+	}
+
+	want := extraIDs
+	if mainID != "" {
+		want = append([]string{mainID}, extraIDs...)
+	}
+	got := make([]string, len(attached))
+	for i, img := range attached {
+		got[i] = img.Source
+	}
+	return slices.Equal(got, want), nil
+}
+
+func (s *Seeder) createProduct(ctx context.Context, prod data.Product) (outcome, error) {
+	images := s.productImageCopies(ctx, prod)
+	imageID := images.mainID()
+	enabled := prod.Enabled
+	if enabled && imageID == "" {
+		slog.Warn("No image found, creating product as disabled", "kind", kindProduct, "id", prod.ID, "name", prod.Name)
+		enabled = false
+	}
+
+	req := &catalogv1.CreateProductRequest{
+		Id:         &prod.ID,
+		Name:       prod.Name,
+		Price:      prod.Price,
+		Quantity:   int32(prod.Quantity),
+		Enabled:    enabled,
+		Attributes: toAttributeValueInputs(prod.Attributes),
+	}
+	if prod.Description != "" {
+		req.Description = &prod.Description
+	}
+	if prod.CategoryID != "" {
+		req.CategoryId = &prod.CategoryID
+	}
+	if imageID != "" {
+		req.ImageId = &imageID
+	}
+
+	resp, err := s.productClient.CreateProduct(ctx, req)
+	if createdByRetry(err) {
+		s.finishProductImages(ctx, prod.ID, images)
+		return outcome{id: prod.ID, action: ActionCreated}, nil
+	}
+	if err != nil {
+		return outcome{}, fmt.Errorf("failed to create product %s: %w", prod.Name, err)
+	}
+
+	s.finishProductImages(ctx, prod.ID, images)
+	return outcome{id: resp.Product.GetId(), action: ActionCreated}, nil
+}
+
+func (s *Seeder) updateProduct(ctx context.Context, prod data.Product, images productImageSet, version int64) (string, error) {
+	imageID := images.mainID()
+	enabled := prod.Enabled
+	if enabled && imageID == "" {
+		slog.Warn("No image found, updating product as disabled", "kind", kindProduct, "id", prod.ID, "name", prod.Name)
+		enabled = false
+	}
+
+	req := &catalogv1.UpdateProductRequest{
+		Id:         prod.ID,
+		Name:       prod.Name,
+		Price:      prod.Price,
+		Quantity:   int32(prod.Quantity),
+		Enabled:    enabled,
+		Version:    version,
+		Attributes: toAttributeValueInputs(prod.Attributes),
+	}
+	if prod.Description != "" {
+		req.Description = &prod.Description
+	}
+	if prod.CategoryID != "" {
+		req.CategoryId = &prod.CategoryID
+	}
+	if imageID != "" {
+		req.ImageId = &imageID
+	}
+
+	resp, err := s.productClient.UpdateProduct(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to update product %s: %w", prod.Name, err)
+	}
+	return resp.Product.GetId(), nil
+}
+
+// resolveProductImages uploads the product's explicit images list and returns the
+// main upload ID plus the IDs of the remaining (gallery/other) uploads. Without an
+// images list it falls back to the single-image convention.
+func (s *Seeder) resolveProductImages(ctx context.Context, prod data.Product) (string, []string) {
+	if len(prod.Images) == 0 {
+		return s.resolveProductImage(ctx, prod), nil
+	}
+
+	var mainID string
+	var extraIDs []string
+	for _, img := range productImages(prod) {
+		imgID := s.tryUploadImage(ctx, img.File, img.Alt, toImageRole(img.Role))
+		if imgID == "" {
+			continue
+		}
+		if img.Role == "main" && mainID == "" {
+			mainID = imgID
+		} else {
+			extraIDs = append(extraIDs, imgID)
+		}
+	}
+	if mainID == "" && s.placeholders {
+		mainID = s.uploadPlaceholder(ctx, prod)
+	}
+	return mainID, extraIDs
+}
+
+// productImages returns the explicit images in sort order with defaults applied:
+// the first image becomes main when none is marked, the rest gallery, and the
+// alt text falls back to the product name.
+func productImages(prod data.Product) []data.ProductImage {
+	images := slices.Clone(prod.Images)
+	slices.SortStableFunc(images, func(a, b data.ProductImage) int {
+		return cmp.Compare(a.SortOrder, b.SortOrder)
+	})
+
+	hasMain := slices.ContainsFunc(images, func(img data.ProductImage) bool { return img.Role == "main" })
+	for i := range images {
+		if images[i].Role == "" {
+			images[i].Role = "gallery"
+			if !hasMain {
+				images[i].Role = "main"
+				hasMain = true
+			}
+		}
+		if images[i].Alt == "" {
+			images[i].Alt = prod.Name
+		}
+	}
+	return images
+}
+
+// productImageSet is what a product write attaches: copies of the product's
+// uploads, main first, and the copies attached earlier that they replace.
+type productImageSet struct {
+	images  []attachedImage
+	hasMain bool
+	stale   []string
+}
+
+func (p productImageSet) mainID() string {
+	if !p.hasMain {
+		return ""
+	}
+	return p.images[0].ID
+}
+
+// productImageCopies uploads the product's images and copies them from the
+// seed draft to the product, so products can share an upload. Copies an
+// earlier run attached are reused for the same upload. When copying fails, the
+// product is written without images.
+func (s *Seeder) productImageCopies(ctx context.Context, prod data.Product) productImageSet {
+	mainID, extraIDs := s.resolveProductImages(ctx, prod)
+	sources := extraIDs
+	if mainID != "" {
+		sources = append([]string{mainID}, extraIDs...)
+	}
+	previous, _ := s.images.productImages(prod.ID)
+
+	set := productImageSet{hasMain: mainID != ""}
+	images, err := s.copyImages(ctx, prod.ID, sources, previous)
+	if err != nil {
+		slog.Warn("Failed to attach images", "kind", kindProduct, "id", prod.ID, "images", len(sources), "error", err)
+		set = productImageSet{}
+	}
+	set.images = images
+	for _, prev := range previous {
+		if !slices.Contains(images, prev) {
+			set.stale = append(set.stale, prev.ID)
+		}
+	}
+	return set
+}
+
+// copyImages returns a copy owned by the product for each source upload,
+// reusing the copies in previous and making the others in one call.
+func (s *Seeder) copyImages(ctx context.Context, productID string, sources []string, previous []attachedImage) ([]attachedImage, error) {
+	images := make([]attachedImage, len(sources))
+	unused := slices.Clone(previous)
+	var missing []string
+	var missingAt []int
+	for i, src := range sources {
+		if j := slices.IndexFunc(unused, func(img attachedImage) bool { return img.Source == src }); j >= 0 {
+			images[i] = unused[j]
+			unused = slices.Delete(unused, j, j+1)
+			continue
+		}
+		missing = append(missing, src)
+		missingAt = append(missingAt, i)
+	}
+	if len(missing) == 0 {
+		return images, nil
+	}
+
+	resp, err := s.imageClient.PromoteImages(ctx, &imagev1.PromoteImagesRequest{
+		ProductId: productID,
+		Move:      false,
+		Images:    missing,
+	})
+	if err != nil {
+		return nil, err
+	}
+	copies := resp.GetPromoted()
+	if len(copies) != len(missing) {
+		return nil, fmt.Errorf("image service copied %d of %d images", len(copies), len(missing))
+	}
+	for k, c := range copies {
+		images[missingAt[k]] = attachedImage{Source: missing[k], ID: c.GetId()}
+		s.uploads.add(c.GetId(), "copy of image "+missing[k])
+	}
+	return images, nil
+}
+
+// finishProductImages runs after a successful product write: it marks the
+// copies and their uploads as claimed so orphan cleanup keeps them, records
+// the copies and deletes the ones they replace.
+func (s *Seeder) finishProductImages(ctx context.Context, productID string, set productImageSet) {
+	for _, img := range set.images {
+		s.uploads.attach(img.Source, img.ID)
+	}
+	s.images.setProductImages(productID, set.images)
+
+	for _, id := range set.stale {
+		if err := s.deleteImage(ctx, id); err != nil {
+			slog.Warn("Failed to delete removed product image", "kind", kindImage, "id", id, "product", productID, "error", err)
+			continue
+		}
+		slog.Info("Deleted removed product image", "kind", kindImage, "id", id, "product", productID, "action", "deleted")
+	}
+}
+
+func (s *Seeder) resolveProductImage(ctx context.Context, prod data.Product) string {
+	if imageFile := prod.ID + ".jpg"; s.imageFileExists(imageFile) {
+		if imgID := s.tryUploadImage(ctx, imageFile, prod.Name, imagev1.ImageRole_IMAGE_ROLE_MAIN); imgID != "" {
+			return imgID
+		}
+	}
+
+	// The category image is shared by its products, so it is described by the
+	// category name rather than that of the first product uploading it.
+	if fallbackFile := categoryImageFile(prod); fallbackFile != "" && s.imageFileExists(fallbackFile) {
+		if imgID := s.tryUploadImage(ctx, fallbackFile, s.categoryName(prod.CategoryID), imagev1.ImageRole_IMAGE_ROLE_MAIN); imgID != "" {
+			return imgID
+		}
+	}
+
+	if s.placeholders {
+		return s.uploadPlaceholder(ctx, prod)
+	}
+	return ""
+}
+
+// hasProductImage reports whether the product would get a main image.
+func (s *Seeder) hasProductImage(prod data.Product) bool {
+	if s.placeholders {
+		return true
+	}
+	if len(prod.Images) > 0 {
+		for _, img := range productImages(prod) {
+			if img.Role == "main" && s.imageFileExists(img.File) {
+				return true
+			}
+		}
+		return false
+	}
+	if s.imageFileExists(prod.ID + ".jpg") {
+		return true
+	}
+	fallbackFile := categoryImageFile(prod)
+	return fallbackFile != "" && s.imageFileExists(fallbackFile)
+}
+
+func (s *Seeder) categoryName(id string) string {
+	for _, cat := range s.data.Categories {
+		if cat.ID == id {
+			return cat.Name
+		}
+	}
+	return ""
+}
+
+func categoryImageFile(prod data.Product) string {
+	if prod.CategoryID == "" {
+		return ""
+	}
+	return fmt.Sprintf("category-%s.jpg", prod.CategoryID)
+}
+
+func (s *Seeder) imageFileExists(filename string) bool {
+	imagePath := filepath.Join(s.assetsDir, filename)
+	_, err := os.Stat(imagePath)
+	return err == nil
+}
+
+func (s *Seeder) tryUploadImage(ctx context.Context, filename, altText string, role imagev1.ImageRole) string {
+	imgID, err := s.uploadImage(ctx, filename, altText, role)
+	if err != nil {
+		slog.Warn("Failed to upload image", "kind", kindImage, "name", filename, "error", err)
+		return ""
+	}
+	return imgID
+}
+
+// currentProduct re-reads an existing product and returns its version and
+// whether it already matches the seed data with the main image mainID.
+func (s *Seeder) currentProduct(ctx context.Context, prod data.Product, mainID string) (int64, bool, error) {
+	existing, err := s.getProduct(ctx, prod.ID)
+	if err != nil {
+		return 0, false, err
+	}
+	if existing == nil {
+		return 0, false, fmt.Errorf("product %s no longer exists", prod.ID)
+	}
+	current := existing.GetImageId() == mainID && len(productDiff(prod, prod.Enabled && mainID != "", existing)) == 0
+	return existing.Version, current, nil
+}
+
+func (s *Seeder) getProduct(ctx context.Context, id string) (*catalogv1.Product, error) {
+	resp, err := s.productClient.GetProductById(ctx, &catalogv1.GetProductByIdRequest{Id: id})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return resp.Product, nil
+}
+
+func toAttributeValueInputs(attrs []data.ProductAttribute) []*catalogv1.AttributeValueInput {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	inputs := make([]*catalogv1.AttributeValueInput, len(attrs))
+	for i, a := range attrs {
+		input := &catalogv1.AttributeValueInput{
+			AttributeId: a.AttributeID,
+		}
+		if a.OptionSlugValue != "" {
+			input.Value = &catalogv1.AttributeValueInput_OptionSlugValue{OptionSlugValue: a.OptionSlugValue}
+		} else if len(a.OptionSlugValues) > 0 {
+			input.Value = &catalogv1.AttributeValueInput_OptionSlugValues{
+				OptionSlugValues: &catalogv1.StringList{Values: a.OptionSlugValues},
+			}
+		} else if a.NumericValue != nil {
+			input.Value = &catalogv1.AttributeValueInput_NumericValue{NumericValue: *a.NumericValue}
+		} else if a.TextValue != "" {
+			input.Value = &catalogv1.AttributeValueInput_TextValue{TextValue: a.TextValue}
+		} else if a.BooleanValue != nil {
+			input.Value = &catalogv1.AttributeValueInput_BooleanValue{BooleanValue: *a.BooleanValue}
+		}
+		inputs[i] = input
+	}
+	return inputs
+}
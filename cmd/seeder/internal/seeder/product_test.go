@@ -0,0 +1,91 @@
+package seeder
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+)
+
+// fakeImageClient copies images by giving each copy a new ID.
+type fakeImageClient struct {
+	imagev1.ImageServiceClient
+	promoted [][]string
+	copies   int
+}
+
+func (c *fakeImageClient) PromoteImages(_ context.Context, req *imagev1.PromoteImagesRequest, _ ...grpc.CallOption) (*imagev1.PromoteImagesResponse, error) {
+	if req.GetMove() {
+		panic("images must be copied, not moved")
+	}
+	c.promoted = append(c.promoted, req.GetImages())
+	resp := &imagev1.PromoteImagesResponse{}
+	for range req.GetImages() {
+		c.copies++
+		resp.Promoted = append(resp.Promoted, &imagev1.Image{Id: "copy" + strconv.Itoa(c.copies), OwnerId: req.GetProductId()})
+	}
+	return resp, nil
+}
+
+func TestCopyImages(t *testing.T) {
+	tests := []struct {
+		name       string
+		sources    []string
+		previous   []attachedImage
+		want       []attachedImage
+		wantCopied []string
+	}{
+		{
+			name:       "first attach",
+			sources:    []string{"a", "b"},
+			want:       []attachedImage{{"a", "copy1"}, {"b", "copy2"}},
+			wantCopied: []string{"a", "b"},
+		},
+		{
+			name:     "unchanged",
+			sources:  []string{"a", "b"},
+			previous: []attachedImage{{"a", "old-a"}, {"b", "old-b"}},
+			want:     []attachedImage{{"a", "old-a"}, {"b", "old-b"}},
+		},
+		{
+			name:       "reordered with a new image",
+			sources:    []string{"c", "a"},
+			previous:   []attachedImage{{"a", "old-a"}, {"b", "old-b"}},
+			want:       []attachedImage{{"c", "copy1"}, {"a", "old-a"}},
+			wantCopied: []string{"c"},
+		},
+		{
+			name:       "same upload twice",
+			sources:    []string{"a", "a"},
+			previous:   []attachedImage{{"a", "old-a"}},
+			want:       []attachedImage{{"a", "old-a"}, {"a", "copy1"}},
+			wantCopied: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeImageClient{}
+			s := &Seeder{imageClient: client, uploads: newUploadTracker()}
+
+			got, err := s.copyImages(context.Background(), "p1", tt.sources, tt.previous)
+			if err != nil {
+				t.Fatalf("copyImages() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("copyImages() = %v, want %v", got, tt.want)
+			}
+			var copied []string
+			for _, ids := range client.promoted {
+				copied = append(copied, ids...)
+			}
+			if !slices.Equal(copied, tt.wantCopied) {
+				t.Errorf("copied %v, want %v", copied, tt.wantCopied)
+			}
+		})
+	}
+}
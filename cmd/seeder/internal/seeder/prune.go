@@ -0,0 +1,306 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+)
+
+const (
+	pruneModeDelete  = "delete"
+	pruneModeDisable = "disable"
+
+	listPageSize = 100
+)
+
+type pruneOptions struct {
+	enabled bool
+	mode    string
+	limit   int
+	force   bool
+}
+
+func (o pruneOptions) validate() error {
+	if o.mode != pruneModeDelete && o.mode != pruneModeDisable {
+		return fmt.Errorf("unknown prune mode %q (expected %s or %s)", o.mode, pruneModeDelete, pruneModeDisable)
+	}
+	return nil
+}
+
+// pruneCatalog removes (or disables) tenant entities whose IDs are not in the seed data,
+// in reverse dependency order: products, then categories, then attributes.
+func (s *Seeder) pruneCatalog(ctx context.Context) error {
+	attributes, err := listAll(func(page int32) ([]*catalogv1.Attribute, int64, error) {
+		resp, err := s.attributeClient.ListAttributes(ctx, &catalogv1.ListAttributesRequest{Page: page, Size: listPageSize})
+		return resp.GetItems(), resp.GetTotal(), err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list attributes: %w", err)
+	}
+	categories, err := listAll(func(page int32) ([]*catalogv1.Category, int64, error) {
+		resp, err := s.categoryClient.ListCategories(ctx, &catalogv1.ListCategoriesRequest{Page: page, Size: listPageSize})
+		return resp.GetItems(), resp.GetTotal(), err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list categories: %w", err)
+	}
+	products, err := listAll(func(page int32) ([]*catalogv1.Product, int64, error) {
+		resp, err := s.productClient.ListProducts(ctx, &catalogv1.ListProductsRequest{Page: page, Size: listPageSize})
+		return resp.GetItems(), resp.GetTotal(), err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list products: %w", err)
+	}
+
+	attributeIDs, categoryIDs, productIDs := s.seedIDs()
+	staleAttributes := pruneCandidates(attributes, attributeIDs, s.prune.mode)
+	staleCategories := pruneCandidates(categories, categoryIDs, s.prune.mode)
+	staleProducts := pruneCandidates(products, productIDs, s.prune.mode)
+
+	total := len(staleAttributes) + len(staleCategories) + len(staleProducts)
+	if total == 0 {
+		slog.Info("Nothing to prune")
+		return nil
+	}
+	if total > s.prune.limit && !s.prune.force {
+		return fmt.Errorf("refusing to %s %d entities (limit %d); rerun with --force to proceed",
+			s.prune.mode, total, s.prune.limit)
+	}
+
+	attributeTypes := make(map[string]catalogv1.AttributeType, len(attributes))
+	for _, a := range attributes {
+		attributeTypes[a.GetId()] = a.GetType()
+	}
+
+	pruneProduct := func(ctx context.Context, p *catalogv1.Product) (Action, error) {
+		return s.pruneProduct(ctx, p, attributeTypes)
+	}
+	if err := pruneAll(ctx, s.results, kindProduct, staleProducts, pruneProduct); err != nil {
+		return err
+	}
+	if err := pruneAll(ctx, s.results, kindCategory, staleCategories, s.pruneCategory); err != nil {
+		return err
+	}
+	return pruneAll(ctx, s.results, kindAttribute, staleAttributes, s.pruneAttribute)
+}
+
+// pruneAll prunes items in order and records each outcome in the run report,
+// stopping at the first failure.
+func pruneAll[T interface {
+	prunable
+	GetName() string
+}](ctx context.Context, r *runReport, kind string, items []T, prune func(context.Context, T) (Action, error)) error {
+	for _, item := range items {
+		start := time.Now()
+		action, err := prune(ctx, item)
+		r.recordSince(ctx, start, kind, item.GetName(), outcome{id: item.GetId(), action: action}, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Seeder) seedIDs() (attributes, categories, products map[string]bool) {
+	attributes = make(map[string]bool, len(s.data.Attributes))
+	for _, a := range s.data.Attributes {
+		attributes[a.ID] = true
+	}
+	categories = make(map[string]bool, len(s.data.Categories))
+	for _, c := range s.data.Categories {
+		categories[c.ID] = true
+	}
+	products = make(map[string]bool, len(s.data.Products))
+	for _, p := range s.data.Products {
+		products[p.ID] = true
+	}
+	return attributes, categories, products
+}
+
+type prunable interface {
+	GetId() string
+	GetEnabled() bool
+}
+
+// pruneCandidates returns listed entities missing from the seed data. In disable
+// mode entities that are already disabled are left alone.
+func pruneCandidates[T prunable](items []T, keep map[string]bool, mode string) []T {
+	var stale []T
+	for _, item := range items {
+		if keep[item.GetId()] {
+			continue
+		}
+		if mode == pruneModeDisable && !item.GetEnabled() {
+			continue
+		}
+		stale = append(stale, item)
+	}
+	return stale
+}
+
+// listAll pages through a List RPC until every item has been fetched.
+func listAll[T any](fetch func(page int32) ([]T, int64, error)) ([]T, error) {
+	var all []T
+	for page := int32(1); ; page++ {
+		items, total, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < listPageSize || int64(len(all)) >= total {
+			return all, nil
+		}
+	}
+}
+
+func (s *Seeder) pruneProduct(ctx context.Context, p *catalogv1.Product, attributeTypes map[string]catalogv1.AttributeType) (Action, error) {
+	if s.prune.mode == pruneModeDelete {
+		if _, err := s.productClient.DeleteProduct(ctx, &catalogv1.DeleteProductRequest{Id: p.GetId()}); err != nil {
+			return "", fmt.Errorf("failed to delete product %s: %w", p.GetName(), err)
+		}
+		return ActionDeleted, nil
+	}
+
+	attributes, err := toExistingAttributeValueInputs(p.GetAttributes(), attributeTypes)
+	if err != nil {
+		return "", fmt.Errorf("failed to disable product %s: %w", p.GetName(), err)
+	}
+	req := &catalogv1.UpdateProductRequest{
+		Id:         p.GetId(),
+		Name:       p.GetName(),
+		Price:      p.GetPrice(),
+		Quantity:   p.GetQuantity(),
+		Enabled:    false,
+		Version:    p.GetVersion(),
+		Attributes: attributes,
+	}
+	if desc := p.GetDescription(); desc != "" {
+		req.Description = &desc
+	}
+	if catID := p.GetCategoryId(); catID != "" {
+		req.CategoryId = &catID
+	}
+	if imgID := p.GetImageId(); imgID != "" {
+		req.ImageId = &imgID
+	}
+
+	if _, err := s.productClient.UpdateProduct(ctx, req); err != nil {
+		return "", fmt.Errorf("failed to disable product %s: %w", p.GetName(), err)
+	}
+	return ActionDisabled, nil
+}
+
+func (s *Seeder) pruneCategory(ctx context.Context, c *catalogv1.Category) (Action, error) {
+	if s.prune.mode == pruneModeDelete {
+		if _, err := s.categoryClient.DeleteCategory(ctx, &catalogv1.DeleteCategoryRequest{Id: c.GetId()}); err != nil {
+			return "", fmt.Errorf("failed to delete category %s: %w", c.GetName(), err)
+		}
+		return ActionDeleted, nil
+	}
+
+	req := &catalogv1.UpdateCategoryRequest{
+		Id:         c.GetId(),
+		Name:       c.GetName(),
+		Enabled:    false,
+		Version:    c.GetVersion(),
+		Attributes: toExistingCategoryAttributeInputs(c.GetAttributes()),
+	}
+	if _, err := s.categoryClient.UpdateCategory(ctx, req); err != nil {
+		return "", fmt.Errorf("failed to disable category %s: %w", c.GetName(), err)
+	}
+	return ActionDisabled, nil
+}
+
+func (s *Seeder) pruneAttribute(ctx context.Context, a *catalogv1.Attribute) (Action, error) {
+	if s.prune.mode == pruneModeDelete {
+		if _, err := s.attributeClient.DeleteAttribute(ctx, &catalogv1.DeleteAttributeRequest{Id: a.GetId()}); err != nil {
+			return "", fmt.Errorf("failed to delete attribute %s: %w", a.GetName(), err)
+		}
+		return ActionDeleted, nil
+	}
+
+	req := &catalogv1.UpdateAttributeRequest{
+		Id:      a.GetId(),
+		Name:    a.GetName(),
+		Enabled: false,
+		Version: a.GetVersion(),
+		Options: toExistingAttributeOptionInputs(a.GetOptions()),
+	}
+	if unit := a.GetUnit(); unit != "" {
+		req.Unit = &unit
+	}
+	if _, err := s.attributeClient.UpdateAttribute(ctx, req); err != nil {
+		return "", fmt.Errorf("failed to disable attribute %s: %w", a.GetName(), err)
+	}
+	return ActionDisabled, nil
+}
+
+func toExistingAttributeOptionInputs(options []*catalogv1.AttributeOption) []*catalogv1.AttributeOptionInput {
+	inputs := make([]*catalogv1.AttributeOptionInput, 0, len(options))
+	for _, opt := range options {
+		input := &catalogv1.AttributeOptionInput{
+			Name: opt.GetName(),
+			Slug: opt.GetSlug(),
+		}
+		if cc := opt.GetColorCode(); cc != "" {
+			input.ColorCode = &cc
+		}
+		if so := opt.GetSortOrder(); so > 0 {
+			input.SortOrder = &so
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+func toExistingCategoryAttributeInputs(attrs []*catalogv1.CategoryAttribute) []*catalogv1.CategoryAttributeInput {
+	inputs := make([]*catalogv1.CategoryAttributeInput, 0, len(attrs))
+	for _, a := range attrs {
+		input := &catalogv1.CategoryAttributeInput{
+			AttributeId: a.GetAttributeId(),
+			Role:        a.GetRole(),
+			Filterable:  a.GetFilterable(),
+			Searchable:  a.GetSearchable(),
+		}
+		if so := a.GetSortOrder(); so > 0 {
+			input.SortOrder = &so
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// toExistingAttributeValueInputs echoes a product's current attribute values back,
+// picking the oneof branch from the attribute type. A value whose attribute was
+// not listed, or has no known type, cannot be echoed; dropping it would erase it.
+func toExistingAttributeValueInputs(values []*catalogv1.AttributeValue, types map[string]catalogv1.AttributeType) ([]*catalogv1.AttributeValueInput, error) {
+	inputs := make([]*catalogv1.AttributeValueInput, 0, len(values))
+	for _, v := range values {
+		input := &catalogv1.AttributeValueInput{AttributeId: v.GetAttributeId()}
+		attrType, ok := types[v.GetAttributeId()]
+		if !ok {
+			return nil, fmt.Errorf("value of unknown attribute %s", v.GetAttributeId())
+		}
+		switch attrType {
+		case catalogv1.AttributeType_ATTRIBUTE_TYPE_SINGLE:
+			input.Value = &catalogv1.AttributeValueInput_OptionSlugValue{OptionSlugValue: v.GetOptionSlugValue()}
+		case catalogv1.AttributeType_ATTRIBUTE_TYPE_MULTIPLE:
+			input.Value = &catalogv1.AttributeValueInput_OptionSlugValues{
+				OptionSlugValues: &catalogv1.StringList{Values: v.GetOptionSlugValues().GetValues()},
+			}
+		case catalogv1.AttributeType_ATTRIBUTE_TYPE_RANGE:
+			input.Value = &catalogv1.AttributeValueInput_NumericValue{NumericValue: v.GetNumericValue()}
+		case catalogv1.AttributeType_ATTRIBUTE_TYPE_BOOLEAN:
+			input.Value = &catalogv1.AttributeValueInput_BooleanValue{BooleanValue: v.GetBooleanValue()}
+		case catalogv1.AttributeType_ATTRIBUTE_TYPE_TEXT:
+			input.Value = &catalogv1.AttributeValueInput_TextValue{TextValue: v.GetTextValue()}
+		default:
+			return nil, fmt.Errorf("attribute %s has unsupported type %s", v.GetAttributeId(), attrType)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,117 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+)
+
+func TestPruneCandidates(t *testing.T) {
+	items := []*catalogv1.Category{
+		{Id: "seeded", Enabled: true},
+		{Id: "stale", Enabled: true},
+		{Id: "stale-disabled"},
+	}
+	keep := map[string]bool{"seeded": true}
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{pruneModeDelete, []string{"stale", "stale-disabled"}},
+		{pruneModeDisable, []string{"stale"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var got []string
+			for _, c := range pruneCandidates(items, keep, tt.mode) {
+				got = append(got, c.GetId())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("pruneCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListAll(t *testing.T) {
+	const total = listPageSize*2 + 5
+	var pages []int32
+	got, err := listAll(func(page int32) ([]int, int64, error) {
+		pages = append(pages, page)
+		n := min(listPageSize, total-int(page-1)*listPageSize)
+		return make([]int, n), total, nil
+	})
+	if err != nil {
+		t.Fatalf("listAll() error = %v", err)
+	}
+	if len(got) != total {
+		t.Errorf("listAll() returned %d items, want %d", len(got), total)
+	}
+	if want := []int32{1, 2, 3}; !slices.Equal(pages, want) {
+		t.Errorf("listAll() fetched pages %v, want %v", pages, want)
+	}
+}
+
+func TestToExistingAttributeValueInputs(t *testing.T) {
+	types := map[string]catalogv1.AttributeType{
+		"color": catalogv1.AttributeType_ATTRIBUTE_TYPE_SINGLE,
+		"size":  catalogv1.AttributeType_ATTRIBUTE_TYPE_RANGE,
+		"odd":   catalogv1.AttributeType_ATTRIBUTE_TYPE_UNSPECIFIED,
+	}
+	value := func(id string) *catalogv1.AttributeValue { return &catalogv1.AttributeValue{AttributeId: id} }
+
+	tests := []struct {
+		name    string
+		values  []*catalogv1.AttributeValue
+		want    []string
+		wantErr bool
+	}{
+		{name: "known types", values: []*catalogv1.AttributeValue{value("color"), value("size")}, want: []string{"color", "size"}},
+		{name: "attribute not listed", values: []*catalogv1.AttributeValue{value("color"), value("gone")}, wantErr: true},
+		{name: "unsupported type", values: []*catalogv1.AttributeValue{value("odd")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs, err := toExistingAttributeValueInputs(tt.values, types)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toExistingAttributeValueInputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, in := range inputs {
+				got = append(got, in.AttributeId)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("toExistingAttributeValueInputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneAllRecordsOutcomes(t *testing.T) {
+	items := []*catalogv1.Attribute{{Id: "a1", Name: "Color"}, {Id: "a2", Name: "Size"}, {Id: "a3", Name: "Weight"}}
+	results := newRunReport()
+
+	err := pruneAll(context.Background(), results, kindAttribute, items, func(_ context.Context, a *catalogv1.Attribute) (Action, error) {
+		if a.GetId() == "a2" {
+			return "", errors.New("boom")
+		}
+		return ActionDeleted, nil
+	})
+	if err == nil {
+		t.Fatal("pruneAll() error = nil, want the failure of a2")
+	}
+
+	var got []string
+	for _, e := range results.entities {
+		got = append(got, e.ID+"="+string(e.Action))
+	}
+	if want := []string{"a1=deleted", "a2=failed"}; !slices.Equal(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/config"
+)
+
+// Polling cadence for WaitReady; each probe gets its own short deadline.
+const (
+	readyPollInterval = 2 * time.Second
+	readyProbeTimeout = 5 * time.Second
+)
+
+// WaitReady blocks until Logto serves its OIDC discovery document and the
+// catalog and image services report SERVING over the gRPC health checking
+// protocol, or until cfg.WaitTimeout elapses. A service without the health
+// service (Unimplemented) counts as ready once it answers.
+func WaitReady(ctx context.Context, cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.WaitTimeout)
+	defer cancel()
+
+	creds, err := transportCredentials(cfg)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Waiting for dependencies", "timeout", cfg.WaitTimeout)
+
+	discovery := strings.TrimRight(cfg.LogtoURL, "/") + "/oidc/.well-known/openid-configuration"
+	httpClient := &http.Client{Timeout: readyProbeTimeout}
+	if err := poll(ctx, "Logto", func(ctx context.Context) error {
+		return probeHTTP(ctx, httpClient, discovery)
+	}); err != nil {
+		return err
+	}
+
+	for _, svc := range []struct{ name, addr string }{
+		{"catalog service", cfg.CatalogGRPCAddr},
+		{"image service", cfg.ImageGRPCAddr},
+	} {
+		conn, err := grpc.NewClient(svc.addr, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", svc.name, err)
+		}
+		err = poll(ctx, svc.name, func(ctx context.Context) error {
+			return probeGRPC(ctx, healthpb.NewHealthClient(conn))
+		})
+		conn.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// poll runs probe until it succeeds or ctx expires, returning the last probe error.
+func poll(ctx context.Context, name string, probe func(context.Context) error) error {
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, readyProbeTimeout)
+		err := probe(probeCtx)
+		cancel()
+		if err == nil {
+			slog.Info("Dependency is ready", "dependency", name)
+			return nil
+		}
+
+		select {
+		case <-time.After(readyPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("%s did not become ready: %w", name, err)
+		}
+	}
+}
+
+func probeHTTP(ctx context.Context, client *http.Client, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+func probeGRPC(ctx context.Context, client healthpb.HealthClient) error {
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("health status %s", resp.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package seeder
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Action is what a run did to a single entity.
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	ActionSkipped   Action = "skipped"
+	ActionFailed    Action = "failed"
+	ActionDeleted   Action = "deleted"
+	ActionDisabled  Action = "disabled"
+)
+
+// Run outcomes and the process exit codes they map to. Failed image uploads
+// are listed in the report but do not make a run partial: the product is still
+// written, without that image, and rerunning the job would not help.
+const (
+	StatusSuccess = "success"
+	StatusPartial = "partial"
+	StatusFailure = "failure"
+
+	ExitSuccess = 0
+	ExitFailure = 1
+	ExitPartial = 2
+)
+
+// Entity kinds in the report, in phase order.
+const (
+	kindAttribute = "attribute"
+	kindCategory  = "category"
+	kindProduct   = "product"
+	kindImage     = "image"
+)
+
+var reportKinds = []string{kindAttribute, kindCategory, kindProduct, kindImage}
+
+// EntityResult is the outcome for one seeded entity.
+type EntityResult struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	ID         string `json:"id,omitempty"`
+	Action     Action `json:"action"`
+	Reason     string `json:"reason,omitempty"`
+	DurationMS int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report is the machine-readable summary of a run.
+type Report struct {
+	Tenant     string                    `json:"tenant,omitempty"`
+	StartedAt  time.Time                 `json:"startedAt"`
+	DurationMS int64                     `json:"durationMs"`
+	Status     string                    `json:"status"`
+	Error      string                    `json:"error,omitempty"`
+	Totals     map[string]map[Action]int `json:"totals"`
+	Conflicts  ConflictTotals            `json:"conflicts"`
+	Entities   []EntityResult            `json:"entities"`
+}
+
+// ConflictTotals mirrors the version conflict counters of the run.
+type ConflictTotals struct {
+	Total       int64 `json:"total"`
+	Overwritten int64 `json:"overwritten"`
+	Skipped     int64 `json:"skipped"`
+}
+
+// ExitCode maps the report status to the process exit code.
+func (r *Report) ExitCode() int {
+	switch r.Status {
+	case StatusSuccess:
+		return ExitSuccess
+	case StatusPartial:
+		return ExitPartial
+	default:
+		return ExitFailure
+	}
+}
+
+// outcome is the result of an upsert that succeeded; reason explains a skip.
+type outcome struct {
+	id     string
+	action Action
+	reason string
+}
+
+// runReport collects entity results concurrently during a run.
+type runReport struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	entities  []EntityResult
+	blocked   map[string]bool // kind + "/" + ID of entities dependents must skip
+}
+
+func newRunReport() *runReport {
+	return &runReport{startedAt: time.Now(), blocked: make(map[string]bool)}
+}
+
+func (r *runReport) record(result EntityResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entities = append(r.entities, result)
+	if result.Action == ActionFailed && result.ID != "" {
+		r.blocked[result.Kind+"/"+result.ID] = true
+	}
+}
+
+// block marks an entity as missing from the catalog after this run, so
+// entities referencing it are skipped. Failed entities are blocked on record.
+func (r *runReport) block(kind, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blocked[kind+"/"+id] = true
+}
+
+func (r *runReport) isBlocked(kind, id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.blocked[kind+"/"+id]
+}
+
+// failures counts the failed catalog entities and image uploads recorded so far.
+func (r *runReport) failures() (entities, images int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entities {
+		switch {
+		case e.Action != ActionFailed:
+		case e.Kind == kindImage:
+			images++
+		default:
+			entities++
+		}
+	}
+	return entities, images
+}
+
+// recordSince records an entity result timed from start, logs it and
+// updates the metrics. A non-nil err overrides the outcome.
+func (r *runReport) recordSince(ctx context.Context, start time.Time, kind, name string, out outcome, err error) {
+	elapsed := time.Since(start)
+	result := EntityResult{
+		Kind:       kind,
+		Name:       name,
+		ID:         out.id,
+		Action:     out.action,
+		Reason:     out.reason,
+		DurationMS: elapsed.Milliseconds(),
+	}
+	if err != nil {
+		result.Action = ActionFailed
+		result.Error = err.Error()
+	}
+	r.record(result)
+	recordEntityMetrics(ctx, result, elapsed)
+
+	attrs := []any{"kind", kind, "id", result.ID, "name", name, "action", result.Action, "duration", elapsed}
+	level := slog.LevelInfo
+	if result.Reason != "" {
+		attrs = append(attrs, "reason", result.Reason)
+	}
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, "error", err)
+	}
+	slog.Log(ctx, level, "Entity "+string(result.Action), attrs...)
+}
+
+// tracked wraps an upsert in a span and records its outcome, duration and
+// error. key returns the entity's name and seed ID, used for failures.
+func tracked[T any](r *runReport, kind string, key func(T) (string, string), fn func(context.Context, T) (outcome, error)) func(context.Context, T) error {
+	return func(ctx context.Context, item T) error {
+		name, id := key(item)
+		ctx, span := tracer.Start(ctx, "upsert "+kind,
+			trace.WithAttributes(attrKind.String(kind), attrName.String(name), attrID.String(id)))
+
+		start := time.Now()
+		out, err := fn(ctx, item)
+		if out.id == "" {
+			out.id = id
+		}
+		r.recordSince(ctx, start, kind, name, out, err)
+
+		if err == nil {
+			span.SetAttributes(attrID.String(out.id), attrAction.String(string(out.action)))
+		}
+		endSpan(span, err)
+		return err
+	}
+}
+
+// Report builds the run report; runErr is the error returned by Run.
+func (s *Seeder) Report(runErr error) *Report {
+	s.results.mu.Lock()
+	entities := append([]EntityResult(nil), s.results.entities...)
+	s.results.mu.Unlock()
+
+	// Workers finish in any order; list entities by phase, then name.
+	slices.SortStableFunc(entities, func(a, b EntityResult) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(reportKinds, a.Kind), slices.Index(reportKinds, b.Kind)),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	report := &Report{
+		Tenant:     s.tenantSlug,
+		StartedAt:  s.results.startedAt,
+		DurationMS: time.Since(s.results.startedAt).Milliseconds(),
+		Status:     StatusSuccess,
+		Totals:     make(map[string]map[Action]int),
+		Conflicts: ConflictTotals{
+			Total:       s.conflicts.total.Load(),
+			Overwritten: s.conflicts.overwritten.Load(),
+			Skipped:     s.conflicts.skipped.Load(),
+		},
+		Entities: entities,
+	}
+
+	for _, e := range entities {
+		if report.Totals[e.Kind] == nil {
+			report.Totals[e.Kind] = make(map[Action]int)
+		}
+		report.Totals[e.Kind][e.Action]++
+		if e.Action == ActionFailed && e.Kind != kindImage {
+			report.Status = StatusPartial
+		}
+	}
+	if runErr != nil {
+		// Failures collected by --keep-going leave the rest of the run intact.
+		var failures *EntityFailures
+		if !errors.As(runErr, &failures) {
+			report.Status = StatusFailure
+		}
+		report.Error = runErr.Error()
+	}
+	return report
+}
+
+// WriteJSON writes the report as indented JSON to path, or stdout for "-".
+func (r *Report) WriteJSON(path string) error {
+	return writeReport(path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	})
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     float64         `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one test suite per entity
+// kind, to path or stdout for "-".
+func (r *Report) WriteJUnit(path string) error {
+	suites := junitTestSuites{
+		Name: "seeder",
+		Time: float64(r.DurationMS) / 1000,
+	}
+	for _, kind := range reportKinds {
+		suite := junitTestSuite{Name: kind}
+		for _, e := range r.Entities {
+			if e.Kind != kind {
+				continue
+			}
+			tc := junitTestCase{
+				ClassName: "seeder." + kind,
+				Name:      e.Name,
+				Time:      float64(e.DurationMS) / 1000,
+				SystemOut: fmt.Sprintf("action=%s id=%s", e.Action, e.ID),
+			}
+			switch e.Action {
+			case ActionFailed:
+				tc.Failure = &junitFailure{Message: e.Error}
+				suite.Failures++
+			case ActionSkipped:
+				tc.Skipped = &struct{}{}
+				suite.Skipped++
+			}
+			suite.Tests++
+			suite.Time += tc.Time
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	return writeReport(path, func(w io.Writer) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(suites); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	})
+}
+
+func writeReport(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	return f.Close()
+}
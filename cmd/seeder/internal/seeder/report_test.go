@@ -0,0 +1,105 @@
+package seeder
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReportStatus(t *testing.T) {
+	created := EntityResult{Kind: kindProduct, Name: "Phone", Action: ActionCreated}
+	failedProduct := EntityResult{Kind: kindProduct, Name: "Laptop", Action: ActionFailed, Error: "boom"}
+	failedImage := EntityResult{Kind: kindImage, Name: "laptop.jpg", Action: ActionFailed, Error: "boom"}
+	keepGoing := &EntityFailures{Errs: []error{errors.New("boom")}}
+
+	tests := []struct {
+		name     string
+		entities []EntityResult
+		runErr   error
+		want     string
+		wantCode int
+	}{
+		{"all written", []EntityResult{created}, nil, StatusSuccess, ExitSuccess},
+		{"failed image upload only", []EntityResult{created, failedImage}, nil, StatusSuccess, ExitSuccess},
+		{"failed entity with keep-going", []EntityResult{created, failedProduct}, keepGoing, StatusPartial, ExitPartial},
+		{"wrapped keep-going failures", []EntityResult{failedProduct}, errors.Join(errors.New("run"), keepGoing), StatusPartial, ExitPartial},
+		{"run aborted", []EntityResult{created, failedProduct}, errors.New("failed to upsert products"), StatusFailure, ExitFailure},
+		{"run aborted before any entity", nil, errors.New("failed to connect"), StatusFailure, ExitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Seeder{results: newRunReport()}
+			for _, e := range tt.entities {
+				s.results.record(e)
+			}
+
+			report := s.Report(tt.runErr)
+			if report.Status != tt.want {
+				t.Errorf("Status = %s, want %s", report.Status, tt.want)
+			}
+			if code := report.ExitCode(); code != tt.wantCode {
+				t.Errorf("ExitCode() = %d, want %d", code, tt.wantCode)
+			}
+			if (tt.runErr != nil) != (report.Error != "") {
+				t.Errorf("Error = %q, want it set only for a run error", report.Error)
+			}
+		})
+	}
+}
+
+func TestReportOrdersAndTotalsEntities(t *testing.T) {
+	s := &Seeder{results: newRunReport()}
+	for _, e := range []EntityResult{
+		{Kind: kindImage, Name: "a.jpg", Action: ActionCreated},
+		{Kind: kindProduct, Name: "Phone", Action: ActionCreated},
+		{Kind: kindAttribute, Name: "Size", Action: ActionUnchanged},
+		{Kind: kindProduct, Name: "Laptop", Action: ActionSkipped},
+		{Kind: kindAttribute, Name: "Color", Action: ActionUnchanged},
+	} {
+		s.results.record(e)
+	}
+
+	report := s.Report(nil)
+	var order []string
+	for _, e := range report.Entities {
+		order = append(order, e.Kind+"/"+e.Name)
+	}
+	want := []string{"attribute/Color", "attribute/Size", "product/Laptop", "product/Phone", "image/a.jpg"}
+	if !slices.Equal(order, want) {
+		t.Errorf("Entities = %v, want %v", order, want)
+	}
+	if got := report.Totals[kindAttribute][ActionUnchanged]; got != 2 {
+		t.Errorf("Totals[attribute][unchanged] = %d, want 2", got)
+	}
+	if got := report.Totals[kindProduct][ActionSkipped]; got != 1 {
+		t.Errorf("Totals[product][skipped] = %d, want 1", got)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := &Report{Entities: []EntityResult{
+		{Kind: kindProduct, Name: "Phone", Action: ActionCreated},
+		{Kind: kindProduct, Name: "Laptop", Action: ActionFailed, Error: "boom"},
+		{Kind: kindProduct, Name: "Tablet", Action: ActionSkipped},
+	}}
+	path := filepath.Join(t.TempDir(), "report.xml")
+	if err := report.WriteJUnit(path); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(raw, &suites); err != nil {
+		t.Fatalf("WriteJUnit() wrote invalid XML: %v", err)
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 {
+		t.Errorf("tests/failures/skipped = %d/%d/%d, want 3/1/1", suites.Tests, suites.Failures, suites.Skipped)
+	}
+}
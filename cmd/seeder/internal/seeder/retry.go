@@ -0,0 +1,158 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backoff bounds between retry attempts; the actual delay is drawn uniformly
+// from zero up to the exponential step ("full jitter").
+const (
+	retryBaseDelay = 200 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+// retryPolicy retries transient failures with exponential backoff and jitter.
+// Each attempt gets its own callTimeout deadline when one is set.
+type retryPolicy struct {
+	maxAttempts int
+	callTimeout time.Duration
+}
+
+// do runs fn until it succeeds, fails with an error retryable rejects, or
+// maxAttempts is reached. The returned error is the last attempt's.
+func (p retryPolicy) do(ctx context.Context, name string, retryable func(error) bool, fn func(context.Context) error) error {
+	attempts := max(p.maxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := p.attempt(ctx, fn)
+		if err == nil || attempt == attempts || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		slog.Warn("Retrying", "call", name, "attempt", attempt+1, "maxAttempts", attempts, "delay", delay.Round(time.Millisecond), "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (p retryPolicy) attempt(ctx context.Context, fn func(context.Context) error) error {
+	if p.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.callTimeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	step := min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+	return time.Duration(rand.Int64N(int64(step) + 1))
+}
+
+// retryInterceptor applies the policy to the unary RPCs retriedRPC accepts;
+// the others only get the per-call deadline. An attempt that timed out may
+// still have been applied, so the error of a call attempted more than once is
+// a *retriedError for callers to recognise that case.
+func retryInterceptor(p retryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		attempts := 0
+		invoke := func(ctx context.Context) error {
+			attempts++
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if !retriedRPC(method) {
+			return p.attempt(ctx, invoke)
+		}
+		err := p.do(ctx, method, retryableRPC, invoke)
+		if err != nil && attempts > 1 {
+			return &retriedError{err: err}
+		}
+		return err
+	}
+}
+
+// retriedRPC reports whether method may be repeated after a transient failure.
+// Reads are harmless. Creates send the seed entity's ID, so a repeat of one
+// that went through fails with AlreadyExists (see createdByRetry); updates
+// carry the entity version, so a repeat fails with Aborted (see
+// updateWithConflictPolicy). A presign is not used until confirmed, and a
+// repeated copy at worst leaves an extra copy. ConfirmUpload is not repeated:
+// its upload token is single-use. method is the full name, e.g.
+// /catalog.v1.ProductService/GetProductById.
+func retriedRPC(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range []string{"Get", "List", "Create", "Update", "PromoteImages"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// retriedError is the error of an RPC attempted more than once.
+type retriedError struct {
+	err error
+}
+
+func (e *retriedError) Error() string { return e.err.Error() }
+func (e *retriedError) Unwrap() error { return e.err }
+
+func wasRetried(err error) bool {
+	var retried *retriedError
+	return errors.As(err, &retried)
+}
+
+// createdByRetry reports whether a create failed with AlreadyExists only
+// because an earlier attempt that reported a transient failure went through.
+func createdByRetry(err error) bool {
+	return wasRetried(err) && status.Code(err) == codes.AlreadyExists
+}
+
+// retryableRPC reports whether a gRPC error is transient. Everything else,
+// e.g. InvalidArgument or NotFound, fails immediately.
+func retryableRPC(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// storageStatusError is a non-2xx response from object storage.
+type storageStatusError struct {
+	op     string
+	status int
+	body   string
+}
+
+func (e *storageStatusError) Error() string {
+	if e.body == "" {
+		return fmt.Sprintf("%s failed with status %d", e.op, e.status)
+	}
+	return fmt.Sprintf("%s failed with status %d: %s", e.op, e.status, e.body)
+}
+
+// retryableStorage reports whether a storage request may succeed on retry:
+// 5xx and 429 responses, and transport errors such as resets or timeouts.
+func retryableStorage(err error) bool {
+	var statusErr *storageStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, context.Canceled)
+}
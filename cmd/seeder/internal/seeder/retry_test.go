@@ -0,0 +1,152 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetriedRPC(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"/catalog.v1.ProductService/GetProductById", true},
+		{"/catalog.v1.CategoryService/ListCategories", true},
+		{"/catalog.v1.AttributeService/UpdateAttribute", true},
+		{"/catalog.v1.ProductService/CreateProduct", true},
+		{"/catalog.v1.AttributeService/CreateAttribute", true},
+		{"/image.v1.ImageService/CreatePresign", true},
+		{"/image.v1.ImageService/PromoteImages", true},
+		{"/image.v1.ImageService/GetDeliveryUrl", true},
+		{"/image.v1.ImageService/ConfirmUpload", false},
+		{"/catalog.v1.ProductService/DeleteProduct", false},
+		{"/image.v1.ImageService/DeleteImage", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if got := retriedRPC(tt.method); got != tt.want {
+				t.Errorf("retriedRPC(%q) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+	exists := status.Error(codes.AlreadyExists, "product exists")
+	invalid := status.Error(codes.InvalidArgument, "bad name")
+
+	tests := []struct {
+		name             string
+		method           string
+		results          []error // per attempt
+		wantCalls        int
+		wantCode         codes.Code
+		wantCreatedRetry bool
+	}{
+		{
+			name: "create retried after a transient failure", method: "/catalog.v1.ProductService/CreateProduct",
+			results: []error{unavailable, nil}, wantCalls: 2, wantCode: codes.OK,
+		},
+		{
+			name: "create applied before the transient failure", method: "/catalog.v1.ProductService/CreateProduct",
+			results: []error{unavailable, exists}, wantCalls: 2, wantCode: codes.AlreadyExists, wantCreatedRetry: true,
+		},
+		{
+			name: "create conflicting on the first attempt", method: "/catalog.v1.ProductService/CreateProduct",
+			results: []error{exists}, wantCalls: 1, wantCode: codes.AlreadyExists,
+		},
+		{
+			name: "permanent failure", method: "/catalog.v1.ProductService/UpdateProduct",
+			results: []error{invalid}, wantCalls: 1, wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "confirm not repeated", method: "/image.v1.ImageService/ConfirmUpload",
+			results: []error{unavailable}, wantCalls: 1, wantCode: codes.Unavailable,
+		},
+		{
+			name: "attempts exhausted", method: "/catalog.v1.ProductService/GetProductById",
+			results: []error{unavailable, unavailable, unavailable}, wantCalls: 3, wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				err := tt.results[min(calls, len(tt.results)-1)]
+				calls++
+				return err
+			}
+
+			intercept := retryInterceptor(retryPolicy{maxAttempts: 3})
+			err := intercept(context.Background(), tt.method, nil, nil, nil, invoker)
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("code = %v, want %v", got, tt.wantCode)
+			}
+			if got := createdByRetry(err); got != tt.wantCreatedRetry {
+				t.Errorf("createdByRetry() = %v, want %v", got, tt.wantCreatedRetry)
+			}
+		})
+	}
+}
+
+func TestRetryableRPC(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), true},
+		{"deadline exceeded", status.Error(codes.DeadlineExceeded, "timeout"), true},
+		{"resource exhausted", status.Error(codes.ResourceExhausted, "rate limited"), true},
+		{"invalid argument", status.Error(codes.InvalidArgument, "bad name"), false},
+		{"not found", status.Error(codes.NotFound, "no such product"), false},
+		{"aborted", status.Error(codes.Aborted, "version mismatch"), false},
+		{"wrapped unavailable", fmt.Errorf("failed to get product: %w", status.Error(codes.Unavailable, "")), true},
+		{"non-gRPC error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableRPC(tt.err); got != tt.want {
+				t.Errorf("retryableRPC(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryableStorage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &storageStatusError{op: "upload", status: http.StatusBadGateway}, true},
+		{"too many requests", &storageStatusError{op: "upload", status: http.StatusTooManyRequests}, true},
+		{"forbidden", &storageStatusError{op: "upload", status: http.StatusForbidden}, false},
+		{"wrapped server error", fmt.Errorf("failed to upload: %w", &storageStatusError{op: "upload", status: 503}), true},
+		{"transport error", io.ErrUnexpectedEOF, true},
+		{"canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableStorage(tt.err); got != tt.want {
+				t.Errorf("retryableStorage(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
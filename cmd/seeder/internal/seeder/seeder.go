@@ -0,0 +1,256 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/auth"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/config"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
+
+	catalogv1 "github.com/Sokol111/ecommerce-catalog-service-api/gen/go/catalog/v1"
+	imagev1 "github.com/Sokol111/ecommerce-image-service-api/gen/go/image/v1"
+)
+
+type Seeder struct {
+	data                *data.SeedData
+	httpClient          *http.Client
+	assetsDir           string
+	storageHostOverride string
+	tenantSlug          string
+	catalogConn         *grpc.ClientConn
+	imageConn           *grpc.ClientConn
+	attributeClient     catalogv1.AttributeServiceClient
+	categoryClient      catalogv1.CategoryServiceClient
+	productClient       catalogv1.ProductServiceClient
+	imageClient         imagev1.ImageServiceClient
+	images              *imageCache
+	checkpoint          *checkpoint
+	uploads             *uploadTracker
+	draftID             string
+	imageOpts           imageOptions
+	placeholders        bool
+	prune               pruneOptions
+	concurrency         int
+	keepGoing           bool
+	retry               retryPolicy
+	conflictPolicy      string
+	conflicts           conflictStats
+	results             *runReport
+}
+
+func New(cfg *config.Config, seedData *data.SeedData, assetsDir string) (*Seeder, error) {
+	prune := pruneOptions{
+		enabled: cfg.Prune,
+		mode:    cfg.PruneMode,
+		limit:   cfg.PruneLimit,
+		force:   cfg.Force,
+	}
+	if prune.enabled {
+		if err := prune.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateConflictPolicy(cfg.ConflictPolicy); err != nil {
+		return nil, err
+	}
+
+	imageOpts := imageOptions{
+		maxBytes:     cfg.MaxImageSize,
+		maxDimension: cfg.MaxImageDimension,
+		format:       cfg.ImageFormat,
+	}
+	if err := imageOpts.validate(); err != nil {
+		return nil, err
+	}
+
+	images, err := newImageCache(cfg.ImageCacheFile, cfg.TenantSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Resume && cfg.CheckpointFile == "" {
+		return nil, fmt.Errorf("--resume requires --checkpoint")
+	}
+	datasetHash, err := seedData.Hash()
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := newCheckpoint(cfg.CheckpointFile, cfg.TenantSlug, datasetHash, cfg.Resume)
+	if err != nil {
+		return nil, err
+	}
+	images.adopt(checkpoint.images())
+
+	retry := retryPolicy{
+		maxAttempts: cfg.MaxAttempts,
+		callTimeout: cfg.CallTimeout,
+	}
+
+	creds, err := transportCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Obtain access token from Logto via client_credentials flow. The provider
+	// caches it and refreshes it before expiry for the rest of the run.
+	tp := auth.NewTokenProvider(cfg.LogtoURL, cfg.ClientID, cfg.ClientSecret, cfg.APIResource)
+	if _, err := tp.Token(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to obtain access token from Logto: %w", err)
+	}
+	slog.Info("Obtained access token from Logto")
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(tp),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(
+			tenantSlugInterceptor(cfg.TenantSlug),
+			retryInterceptor(retry),
+			tp.RetryUnauthenticated(),
+			rateLimitInterceptor(newRateLimiter(cfg.QPS)),
+		),
+	}
+
+	catalogConn, err := grpc.NewClient(cfg.CatalogGRPCAddr, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to catalog service: %w", err)
+	}
+
+	imageConn, err := grpc.NewClient(cfg.ImageGRPCAddr, dialOpts...)
+	if err != nil {
+		catalogConn.Close()
+		return nil, fmt.Errorf("failed to connect to image service: %w", err)
+	}
+
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &Seeder{
+		data:                seedData,
+		httpClient:          httpClient,
+		assetsDir:           assetsDir,
+		storageHostOverride: cfg.StorageHostOverride,
+		tenantSlug:          cfg.TenantSlug,
+		catalogConn:         catalogConn,
+		imageConn:           imageConn,
+		attributeClient:     catalogv1.NewAttributeServiceClient(catalogConn),
+		categoryClient:      catalogv1.NewCategoryServiceClient(catalogConn),
+		productClient:       catalogv1.NewProductServiceClient(catalogConn),
+		imageClient:         imagev1.NewImageServiceClient(imageConn),
+		images:              images,
+		checkpoint:          checkpoint,
+		uploads:             newUploadTracker(),
+		draftID:             draftOwnerPrefix + time.Now().Format("20060102150405"),
+		imageOpts:           imageOpts,
+		placeholders:        cfg.PlaceholderImages,
+		prune:               prune,
+		concurrency:         cfg.Concurrency,
+		keepGoing:           cfg.KeepGoing,
+		retry:               retry,
+		conflictPolicy:      cfg.ConflictPolicy,
+		results:             newRunReport(),
+	}, nil
+}
+
+// tenantSlugInterceptor adds the x-tenant-slug header to every call when a tenant is set.
+func tenantSlugInterceptor(tenantSlug string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if tenantSlug != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-slug", tenantSlug)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (s *Seeder) Close() {
+	s.catalogConn.Close()
+	s.imageConn.Close()
+}
+
+func (s *Seeder) Run(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "seed", trace.WithAttributes(attrTenant.String(s.tenantSlug)))
+	defer func() { endSpan(span, err) }()
+
+	slog.Info("Starting demo data seeder")
+
+	defer func() {
+		if err := s.images.save(); err != nil {
+			slog.Warn("Failed to save image cache", "error", err)
+		}
+	}()
+	defer s.cleanupOrphanedImages(ctx)
+
+	// With --keep-going, phase errors are collected and the next phase still
+	// runs; entities depending on a failed one are skipped.
+	var failures []error
+	phase := func(name string, upsert func(context.Context) error) error {
+		ctx, span := tracer.Start(ctx, "seed "+name)
+		err := upsert(ctx)
+		endSpan(span, err)
+		if err != nil && s.keepGoing && ctx.Err() == nil {
+			failures = append(failures, err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to upsert %s: %w", name, err)
+		}
+		return nil
+	}
+
+	slog.Info("Upserting attributes")
+	if err := phase("attributes", s.upsertAttributes); err != nil {
+		return err
+	}
+
+	slog.Info("Upserting categories")
+	if err := phase("categories", s.upsertCategories); err != nil {
+		return err
+	}
+
+	slog.Info("Upserting products")
+	if err := phase("products", s.upsertProducts); err != nil {
+		return err
+	}
+
+	if s.prune.enabled {
+		slog.Info("Pruning entities missing from seed data", "mode", s.prune.mode)
+		ctx, span := tracer.Start(ctx, "prune", trace.WithAttributes(attribute.String("seeder.prune.mode", s.prune.mode)))
+		err := s.pruneCatalog(ctx)
+		endSpan(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to prune catalog: %w", err)
+		}
+	}
+
+	slog.Info("Version conflicts",
+		"total", s.conflicts.total.Load(), "overwritten", s.conflicts.overwritten.Load(), "skipped", s.conflicts.skipped.Load())
+	failed, failedImages := s.results.failures()
+	if failed > 0 || len(failures) > 0 {
+		slog.Warn("Demo data seeding completed with failures", "failed", failed, "failedImages", failedImages)
+		// The report marks the run partial, so Run must not return nil.
+		if len(failures) == 0 {
+			failures = append(failures, fmt.Errorf("%d entities failed", failed))
+		}
+		return &EntityFailures{Errs: failures}
+	}
+	if failedImages > 0 {
+		slog.Warn("Some images failed to upload; their products were written without them", "failedImages", failedImages)
+	}
+	if err := s.checkpoint.remove(); err != nil {
+		slog.Warn("Failed to remove checkpoint", "error", err)
+	}
+	slog.Info("Demo data seeding completed successfully")
+	return nil
+}
@@ -0,0 +1,66 @@
+package seeder
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/Sokol111/ecommerce-infrastructure/cmd/seeder"
+
+// Span attribute keys shared by the run, phase and entity spans.
+const (
+	attrTenant = attribute.Key("seeder.tenant")
+	attrKind   = attribute.Key("seeder.entity.kind")
+	attrName   = attribute.Key("seeder.entity.name")
+	attrID     = attribute.Key("seeder.entity.id")
+	attrAction = attribute.Key("seeder.entity.action")
+)
+
+// The global providers delegate to the SDK once telemetry.Setup installs it,
+// so instruments can be created at package init. Creation only fails for
+// invalid names, which are constants here.
+var (
+	tracer = otel.Tracer(instrumentationName)
+	meter  = otel.Meter(instrumentationName)
+
+	entityCounter, _ = meter.Int64Counter("seeder.entities",
+		metric.WithDescription("Entities processed, by kind and action"),
+		metric.WithUnit("{entity}"))
+	errorCounter, _ = meter.Int64Counter("seeder.errors",
+		metric.WithDescription("Entities that failed, by kind"),
+		metric.WithUnit("{error}"))
+	entityDuration, _ = meter.Float64Histogram("seeder.entity.duration",
+		metric.WithDescription("Time to process one entity, by kind and action"),
+		metric.WithUnit("s"))
+	uploadBytes, _ = meter.Int64Counter("seeder.upload.size",
+		metric.WithDescription("Bytes uploaded to object storage"),
+		metric.WithUnit("By"))
+	uploadDuration, _ = meter.Float64Histogram("seeder.upload.duration",
+		metric.WithDescription("Time to upload one image to object storage, including retries"),
+		metric.WithUnit("s"))
+)
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// recordEntityMetrics counts an entity result and its processing time.
+func recordEntityMetrics(ctx context.Context, result EntityResult, elapsed time.Duration) {
+	attrs := metric.WithAttributes(attrKind.String(result.Kind), attrAction.String(string(result.Action)))
+	entityCounter.Add(ctx, 1, attrs)
+	entityDuration.Record(ctx, elapsed.Seconds(), attrs)
+	if result.Action == ActionFailed {
+		errorCounter.Add(ctx, 1, metric.WithAttributes(attrKind.String(result.Kind)))
+	}
+}
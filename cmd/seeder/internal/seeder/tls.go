@@ -0,0 +1,64 @@
+package seeder
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/config"
+)
+
+// transportCredentials builds the gRPC transport security shared by the
+// catalog and image connections: TLS verified against the system roots and/or
+// a CA bundle, a client certificate for mTLS, or plaintext when opted in.
+func transportCredentials(cfg *config.Config) (credentials.TransportCredentials, error) {
+	if cfg.GRPCPlaintext {
+		if cfg.GRPCCAFile != "" || cfg.GRPCCertFile != "" || cfg.GRPCKeyFile != "" || cfg.GRPCServerName != "" {
+			return nil, fmt.Errorf("--grpc-plaintext cannot be combined with TLS settings")
+		}
+		return insecure.NewCredentials(), nil
+	}
+
+	roots := x509.NewCertPool()
+	if cfg.GRPCSystemRoots {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system root CAs: %w", err)
+		}
+		roots = pool
+	}
+	if cfg.GRPCCAFile != "" {
+		pem, err := os.ReadFile(cfg.GRPCCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.GRPCCAFile)
+		}
+	} else if !cfg.GRPCSystemRoots {
+		return nil, fmt.Errorf("--grpc-ca-file is required when system roots are disabled")
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    roots,
+		ServerName: cfg.GRPCServerName,
+	}
+
+	if (cfg.GRPCCertFile == "") != (cfg.GRPCKeyFile == "") {
+		return nil, fmt.Errorf("--grpc-cert-file and --grpc-key-file must be set together")
+	}
+	if cfg.GRPCCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.GRPCCertFile, cfg.GRPCKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsCfg), nil
+}
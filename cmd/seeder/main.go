@@ -2,33 +2,180 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/config"
 	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/data"
 	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/seeder"
+	"github.com/Sokol111/ecommerce-infrastructure/cmd/seeder/internal/telemetry"
 )
 
+// telemetryFlushTimeout bounds flushing spans and metrics on exit.
+const telemetryFlushTimeout = 5 * time.Second
+
+//go:generate go run . schema
+
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	args := config.Parse()
+	args, err := config.Parse()
+	if err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
+	if err := setupLogging(args); err != nil {
+		fatal("Invalid logging options", "error", err)
+	}
+
+	if args.Command == config.CommandSchema {
+		if err := data.WriteSchemas(args.SchemaDir); err != nil {
+			fatal("Failed to write JSON schemas", "error", err)
+		}
+		slog.Info("JSON schemas written", "dir", args.SchemaDir)
+		return
+	}
+
+	if args.Command == config.CommandGCImages {
+		waitReady(ctx, args.Config)
+
+		s, err := seeder.New(args.Config, &data.SeedData{}, args.AssetsDir)
+		if err != nil {
+			fatal("Failed to create seeder", "error", err)
+		}
+		defer s.Close()
+
+		if err := s.GCImages(ctx, args.Config.GCMinAge); err != nil {
+			fatal("Image cleanup failed", "error", err)
+		}
+		return
+	}
 
-	seedData, err := data.LoadFromDir(args.DataDir)
+	seedData, err := data.LoadFromDir(args.DataDir, args.Config.TenantSlug)
 	if err != nil {
-		log.Fatalf("Failed to load seed data: %v", err)
+		fatal("Failed to load seed data", "error", err)
 	}
 
+	if err := data.Validate(seedData); err != nil {
+		fatal("Invalid seed data", "error", err)
+	}
+
+	switch args.Command {
+	case config.CommandValidate:
+		slog.Info("Seed data is valid",
+			"attributes", len(seedData.Attributes), "categories", len(seedData.Categories), "products", len(seedData.Products))
+		return
+	case config.CommandSeed:
+	default:
+		fatal(fmt.Sprintf("Unknown command %q (expected %s, %s, %s or %s)", args.Command,
+			config.CommandSeed, config.CommandValidate, config.CommandSchema, config.CommandGCImages))
+	}
+
+	flushTelemetry := setupTelemetry(ctx)
+	defer flushTelemetry()
+
+	waitReady(ctx, args.Config)
+
 	s, err := seeder.New(args.Config, seedData, args.AssetsDir)
 	if err != nil {
-		log.Fatalf("Failed to create seeder: %v", err)
+		fatal("Failed to create seeder", "error", err)
 	}
 	defer s.Close()
 
-	if err := s.Run(ctx); err != nil {
-		log.Fatalf("Seeding failed: %v", err)
+	if args.Plan {
+		if err := s.Plan(ctx); err != nil {
+			fatal("Planning failed", "error", err)
+		}
+		return
+	}
+
+	runErr := s.Run(ctx)
+	report := s.Report(runErr)
+	writeReports(args, report)
+	if runErr != nil {
+		slog.Error("Seeding failed", "error", runErr)
+	}
+	if code := report.ExitCode(); code != seeder.ExitSuccess {
+		s.Close()
+		flushTelemetry()
+		os.Exit(code)
+	}
+}
+
+// setupTelemetry starts OTLP export when configured through OTEL_* env vars and
+// returns a func that flushes it. Telemetry problems never fail the run.
+func setupTelemetry(ctx context.Context) func() {
+	shutdown, err := telemetry.Setup(ctx)
+	if err != nil {
+		slog.Warn("Telemetry disabled", "error", err)
+		return func() {}
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), telemetryFlushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Warn("Failed to flush telemetry", "error", err)
+		}
+	}
+}
+
+// writeReports writes the run report in the requested formats. A report that
+// cannot be written is logged but does not change the exit code.
+func writeReports(args *config.Args, report *seeder.Report) {
+	if args.ReportFile != "" {
+		if err := report.WriteJSON(args.ReportFile); err != nil {
+			slog.Warn("Failed to write report", "error", err)
+		}
+	}
+	if args.JUnitFile != "" {
+		if err := report.WriteJUnit(args.JUnitFile); err != nil {
+			slog.Warn("Failed to write JUnit report", "error", err)
+		}
+	}
+}
+
+// waitReady gates startup on dependency readiness unless --wait-timeout is 0.
+func waitReady(ctx context.Context, cfg *config.Config) {
+	if cfg.WaitTimeout <= 0 {
+		return
 	}
+	if err := seeder.WaitReady(ctx, cfg); err != nil {
+		fatal("Dependencies not ready", "error", err)
+	}
+}
+
+// setupLogging installs the default slog logger in the requested format and
+// level. The tenant, when set, is attached to every line.
+func setupLogging(args *config.Args) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(args.LogLevel)); err != nil {
+		return fmt.Errorf("invalid log level %q: expected debug, info, warn or error", args.LogLevel)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch args.LogFormat {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid log format %q: expected text or json", args.LogFormat)
+	}
+
+	logger := slog.New(handler)
+	if args.Config.TenantSlug != "" {
+		logger = logger.With("tenant", args.Config.TenantSlug)
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }